@@ -0,0 +1,157 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// FilterCondition is a criterion of an autofilter column.
+type FilterCondition struct {
+	Operator Operator
+	// Value is a float64, string or bool, or a string such as "#N/A"
+	// for error values. It is nil for the blank conditions.
+	Value     interface{}
+	Blanks    bool // Matches empty cells.
+	NonBlanks bool // Matches cells that are not empty.
+}
+
+// FilterColumn holds the criteria of a column of an autofilter.
+type FilterColumn struct {
+	// Col is the zero-based column of the sheet.
+	Col int
+	// Or joins the conditions with OR rather than AND.
+	Or         bool
+	Conditions []FilterCondition
+
+	// Top10 filters the TopN largest, or smallest unless Top, values or
+	// percent of values.
+	Top10   bool
+	Top     bool
+	Percent bool
+	TopN    int
+}
+
+// AutoFilter is the autofilter of a worksheet.
+type AutoFilter struct {
+	// Range holds the header row and the filtered rows.
+	Range CellRange
+	// Active is set when the filter hides rows.
+	Active  bool
+	Columns []FilterColumn
+}
+
+// filterOperators maps DOPER comparison codes to operators.
+var filterOperators = map[byte]Operator{
+	1: OperatorLess,
+	2: OperatorEqual,
+	3: OperatorLessOrEqual,
+	4: OperatorGreater,
+	5: OperatorNotEqual,
+	6: OperatorGreaterOrEqual,
+}
+
+// parseAutoFilter reads an AUTOFILTER record. Its column is relative to
+// the first column of the filter range until setFilterRange.
+func (w *WorkSheet) parseAutoFilter(bts []byte) FilterColumn {
+	if len(bts) < 24 {
+		return FilterColumn{}
+	}
+	flags := binary.LittleEndian.Uint16(bts[2:])
+	fc := FilterColumn{
+		Col:     int(binary.LittleEndian.Uint16(bts)),
+		Or:      flags&0x3 == 1,
+		Top10:   flags&0x10 != 0,
+		Top:     flags&0x20 != 0,
+		Percent: flags&0x40 != 0,
+		TopN:    int(flags >> 7),
+	}
+	if fc.Top10 {
+		return fc
+	}
+	buf := bytes.NewReader(bts[24:])
+	for _, doper := range [][]byte{bts[4:14], bts[14:24]} {
+		c := FilterCondition{Operator: filterOperators[doper[1]]}
+		switch doper[0] {
+		case 0x00: // Not used.
+			continue
+		case 0x02: // RK number.
+			c.Value = rkFloat(RK(binary.LittleEndian.Uint32(doper[2:])))
+		case 0x04:
+			c.Value = math.Float64frombits(binary.LittleEndian.Uint64(doper[2:]))
+		case 0x06:
+			c.Value, _ = w.wb.getString(buf, uint16(doper[6]))
+		case 0x08:
+			if doper[3] != 0 {
+				c.Value = errorText[doper[2]]
+			} else {
+				c.Value = doper[2] != 0
+			}
+		case 0x0C:
+			c.Blanks = true
+		case 0x0E:
+			c.NonBlanks = true
+		}
+		fc.Conditions = append(fc.Conditions, c)
+	}
+	return fc
+}
+
+// setFilterRange sets the autofilter range from the _FilterDatabase name
+// of the sheet.
+func (w *WorkSheet) setFilterRange() {
+	if w.AutoFilter == nil {
+		return
+	}
+	sheet := -1
+	for i, s := range w.wb.sheets {
+		if s == w {
+			sheet = i
+		}
+	}
+	n, ok := w.wb.Name(NameFilterDatabase, sheet)
+	if !ok || n.Sheet != sheet || len(n.rgce) == 0 {
+		return
+	}
+	// The name refers to an area of the sheet.
+	rgce := n.rgce
+	switch rgce[0] &^ 0x60 {
+	case 0x1B: // ptgArea3d
+		if len(rgce) < 11 {
+			return
+		}
+		rgce = rgce[3:]
+	case 0x05: // ptgArea
+		if len(rgce) < 9 {
+			return
+		}
+		rgce = rgce[1:]
+	default:
+		return
+	}
+	r := CellRange{
+		FirstRowB: binary.LittleEndian.Uint16(rgce),
+		LastRowB:  binary.LittleEndian.Uint16(rgce[2:]),
+		FristColB: binary.LittleEndian.Uint16(rgce[4:]) & 0xFF,
+		LastColB:  binary.LittleEndian.Uint16(rgce[6:]) & 0xFF,
+	}
+	w.AutoFilter.Range = r
+	for i := range w.AutoFilter.Columns {
+		w.AutoFilter.Columns[i].Col += int(r.FristColB)
+	}
+}
+
+// FilteredRows returns the zero-based rows hidden by the autofilter.
+func (w *WorkSheet) FilteredRows() []int {
+	af := w.AutoFilter
+	if af == nil || !af.Active {
+		return nil
+	}
+	var rows []int
+	for i := int(af.Range.FirstRowB) + 1; i <= int(af.Range.LastRowB); i++ {
+		if r := w.Row(i); r != nil && r.Hidden() {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,95 @@
+package xls
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAutoFilter(t *testing.T) {
+	wb := &WorkBook{Formats: make(map[uint16]*Format)}
+	other := &WorkSheet{wb: wb}
+	s := &WorkSheet{wb: wb}
+	wb.sheets = []*WorkSheet{other, s}
+
+	// Hidden built-in _FilterDatabase name of the second sheet for B1:D20.
+	area := cat([]byte{0x3B}, le16(0, 0, 19, 1, 3))
+	wb.parseName(cat(le16(0x21), []byte{0, 1}, le16(uint16(len(area)), 0, 2, 0, 0), []byte{0, 0x0D}, area))
+	wb.parseName(cat(le16(0), []byte{0, 5}, le16(3, 0, 0, 0, 0), []byte{0}, []byte("Total"), []byte{0x1E}, le16(7)))
+	wb.resolveNames()
+	if len(wb.Names) != 2 || wb.Names[0].Name != NameFilterDatabase || !wb.Names[0].Builtin || wb.Names[0].Sheet != 1 {
+		t.Fatalf("names = %+v", wb.Names)
+	}
+	if n, ok := wb.Name("total", 0); !ok || n.Sheet != -1 || n.Formula != "7" {
+		t.Errorf("Total = %+v, %v", n, ok)
+	}
+	if got := wb.definedName(2); got != "Total" {
+		t.Errorf("definedName(2) = %q", got)
+	}
+
+	east := cat(le16(0, 0x4), []byte{6, 2, 0, 0, 0, 0, 4, 1, 0, 0}, make([]byte, 10), []byte{0}, []byte("East"))
+	num := ptgNum(10)[1:]
+	or := cat(le16(1, 0x1), []byte{4, 6}, num, []byte{0x0C, 0}, make([]byte, 8))
+	top := cat(le16(2, 0x10|0x20|5<<7), make([]byte, 20))
+	var stream []byte
+	for _, r := range [][]byte{
+		biffRecord(0x09B, nil),
+		biffRecord(0x09D, le16(3)),
+		biffRecord(0x09E, east),
+		biffRecord(0x09E, or),
+		biffRecord(0x09E, top),
+		biffRecord(0x1B2, make([]byte, 18)),
+		rowRecord(0, 255, 0x100),
+		rowRecord(3, 255, 0x100|rowHidden),
+		rowRecord(7, 255, 0x100|rowHidden),
+		rowRecord(30, 255, 0x100|rowHidden),
+		biffRecord(0x0A, nil),
+	} {
+		stream = append(stream, r...)
+	}
+	if err := s.parse(bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+
+	af := s.AutoFilter
+	if af == nil || !af.Active || af.Range != (CellRange{0, 19, 1, 3}) || len(af.Columns) != 3 {
+		t.Fatalf("autofilter = %+v", af)
+	}
+	c := af.Columns[0]
+	if c.Col != 1 || c.Or || len(c.Conditions) != 1 || c.Conditions[0] != (FilterCondition{Operator: OperatorEqual, Value: "East"}) {
+		t.Errorf("column B = %+v", c)
+	}
+	c = af.Columns[1]
+	if c.Col != 2 || !c.Or || len(c.Conditions) != 2 || c.Conditions[0].Value != 10.0 ||
+		c.Conditions[0].Operator != OperatorGreaterOrEqual || !c.Conditions[1].Blanks {
+		t.Errorf("column C = %+v", c)
+	}
+	c = af.Columns[2]
+	if c.Col != 3 || !c.Top10 || !c.Top || c.Percent || c.TopN != 5 {
+		t.Errorf("column D = %+v", c)
+	}
+	if rows := s.FilteredRows(); len(rows) != 2 || rows[0] != 3 || rows[1] != 7 {
+		t.Errorf("filtered rows = %v", rows)
+	}
+
+	if sheetFromRecords(t, nil).AutoFilter != nil {
+		t.Error("unexpected autofilter")
+	}
+}
+
+func TestAutoFilterArea(t *testing.T) {
+	wb := &WorkBook{Formats: make(map[uint16]*Format)}
+	s := &WorkSheet{wb: wb}
+	wb.sheets = []*WorkSheet{s}
+
+	// _FilterDatabase as a plain ptgArea for C1:E10.
+	area := cat([]byte{0x25}, le16(0, 9, 2, 4))
+	wb.parseName(cat(le16(0x21), []byte{0, 1}, le16(uint16(len(area)), 0, 1, 0, 0), []byte{0, 0x0D}, area))
+	wb.resolveNames()
+	stream := cat(biffRecord(0x09D, le16(3)), biffRecord(0x0A, nil))
+	if err := s.parse(bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+	if af := s.AutoFilter; af == nil || af.Range != (CellRange{0, 9, 2, 4}) {
+		t.Fatalf("autofilter = %+v", af)
+	}
+}
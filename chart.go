@@ -0,0 +1,294 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// ChartType is the kind of the first chart group of a chart.
+type ChartType byte
+
+const (
+	ChartUnknown ChartType = iota
+	ChartColumn
+	ChartBar // Horizontal bars.
+	ChartLine
+	ChartArea
+	ChartPie
+	ChartDoughnut
+	ChartPieOfPie
+	ChartScatter
+	ChartBubble
+	ChartRadar
+	ChartFilledRadar
+	ChartSurface
+)
+
+// ChartSeries is a data series of a chart. References are formulas such
+// as Sheet1!$B$2:$B$10, empty when the chart holds the data itself.
+type ChartSeries struct {
+	// Name is the literal name of the series and NameRef the cell
+	// holding it.
+	Name        string
+	NameRef     string
+	Categories  string
+	Values      string
+	BubbleSizes string
+}
+
+// Chart is a chart sheet or a chart embedded in a worksheet.
+type Chart struct {
+	Type    ChartType
+	Stacked bool
+	Percent bool // Stacked to 100%.
+	ThreeD  bool
+
+	Title string
+	// Axis titles of the category (X), value (Y) and series (Z) axes.
+	CategoryAxisTitle string
+	ValueAxisTitle    string
+	SeriesAxisTitle   string
+
+	Series []ChartSeries
+
+	// Name and Anchor place an embedded chart on its sheet.
+	Name   string
+	Anchor Anchor
+}
+
+// object is an OBJ record of a sheet. OBJ records follow their shapes in
+// the drawing.
+type object struct {
+	typ   uint16
+	id    uint16
+	chart int // One-based index in Charts of an embedded chart.
+
+	// storage identifies the MBD storage of an embedded object.
+	storage  uint32
+	embedded bool
+	class    string
+}
+
+// parseObj reads the subrecords of an OBJ record.
+func (w *WorkSheet) parseObj(bts []byte) object {
+	var obj object
+	var pictFlags uint16
+	for len(bts) >= 4 {
+		ft := binary.LittleEndian.Uint16(bts)
+		cb := int(binary.LittleEndian.Uint16(bts[2:]))
+		if ft == 0x00 { // ftEnd
+			break
+		}
+		if cb > len(bts)-4 {
+			cb = len(bts) - 4
+		}
+		data := bts[4 : 4+cb]
+		bts = bts[4+cb:]
+		switch ft {
+		case 0x15: // ftCmo
+			if len(data) >= 4 {
+				obj.typ = binary.LittleEndian.Uint16(data)
+				obj.id = binary.LittleEndian.Uint16(data[2:])
+			}
+		case 0x08: // ftPioGrbit
+			if len(data) >= 2 {
+				pictFlags = binary.LittleEndian.Uint16(data)
+			}
+		case 0x09: // ftPictFmla
+			w.parsePictFmla(&obj, data, pictFlags)
+		}
+	}
+	return obj
+}
+
+// Chart text link targets.
+const (
+	linkTitle        = 1
+	linkValueAxis    = 2
+	linkCategoryAxis = 3
+	linkSeriesAxis   = 7
+)
+
+// parseChart reads the records of a chart substream after its BOF up to
+// its EOF.
+func (w *WorkSheet) parseChart(buf io.Reader) (Chart, error) {
+	var c Chart
+	var (
+		depth       int
+		series      *ChartSeries
+		seriesDepth int
+		inText      bool
+		textDepth   int
+		textLink    uint16
+		text        string
+	)
+	b := new(bof)
+	for {
+		if err := binary.Read(buf, binary.LittleEndian, b); err != nil {
+			return c, err
+		}
+		bts := make([]byte, b.Size)
+		if _, err := io.ReadFull(buf, bts); err != nil {
+			return c, err
+		}
+		switch b.ID {
+		case 0x00A: // EOF
+			return c, nil
+		case 0x1033: // BEGIN
+			depth++
+		case 0x1034: // END
+			depth--
+			if inText && depth == textDepth {
+				inText = false
+				switch textLink {
+				case linkTitle:
+					c.Title = text
+				case linkValueAxis:
+					c.ValueAxisTitle = text
+				case linkCategoryAxis:
+					c.CategoryAxisTitle = text
+				case linkSeriesAxis:
+					c.SeriesAxisTitle = text
+				}
+			}
+			if series != nil && depth == seriesDepth {
+				series = nil
+			}
+		case 0x1003: // SERIES
+			c.Series = append(c.Series, ChartSeries{})
+			series = &c.Series[len(c.Series)-1]
+			seriesDepth = depth
+		case 0x1025: // TEXT
+			inText, textDepth, textLink, text = true, depth, 0, ""
+		case 0x1027: // OBJECTLINK
+			if inText && len(bts) >= 2 {
+				textLink = binary.LittleEndian.Uint16(bts)
+			}
+		case 0x100D: // SERIESTEXT
+			s := w.seriesText(bts)
+			switch {
+			case inText:
+				text = s
+			case series != nil:
+				series.Name = s
+			}
+		case 0x1051: // BRAI
+			if series != nil && !inText {
+				w.parseBRAI(bts, series)
+			}
+		case 0x103A: // CHART3D
+			c.ThreeD = true
+		default:
+			if c.Type == ChartUnknown {
+				c.setType(b.ID, bts)
+			}
+		}
+	}
+}
+
+// seriesText reads a SERIESTEXT record.
+func (w *WorkSheet) seriesText(bts []byte) string {
+	if len(bts) < 3 {
+		return ""
+	}
+	s, _ := w.wb.getString(bytes.NewReader(bts[3:]), uint16(bts[2]))
+	return s
+}
+
+// parseBRAI reads a reference to the name, values, categories or bubble
+// sizes of a series.
+func (w *WorkSheet) parseBRAI(bts []byte, s *ChartSeries) {
+	if len(bts) < 8 || bts[1] != 2 { // Only references to cells.
+		return
+	}
+	cce := int(binary.LittleEndian.Uint16(bts[6:]))
+	if cce > len(bts)-8 {
+		return
+	}
+	f, err := formulaText(bts[8:8+cce], nil, cellRef{}, w.wb)
+	if err != nil {
+		return
+	}
+	switch bts[0] {
+	case 0:
+		s.NameRef = f
+	case 1:
+		s.Values = f
+	case 2:
+		s.Categories = f
+	case 3:
+		s.BubbleSizes = f
+	}
+}
+
+// setType sets the chart type from a chart group type record.
+func (c *Chart) setType(id uint16, bts []byte) {
+	flag := func(off int) uint16 {
+		if len(bts) < off+2 {
+			return 0
+		}
+		return binary.LittleEndian.Uint16(bts[off:])
+	}
+	switch id {
+	case 0x1017: // BAR
+		f := flag(4)
+		c.Type = ChartColumn
+		if f&0x1 != 0 {
+			c.Type = ChartBar
+		}
+		c.Stacked, c.Percent = f&0x2 != 0, f&0x4 != 0
+	case 0x1018, 0x101A: // LINE, AREA
+		f := flag(0)
+		c.Type = ChartLine
+		if id == 0x101A {
+			c.Type = ChartArea
+		}
+		c.Stacked, c.Percent = f&0x1 != 0, f&0x2 != 0
+	case 0x1019: // PIE
+		c.Type = ChartPie
+		if flag(2) != 0 {
+			c.Type = ChartDoughnut
+		}
+	case 0x1061: // BOPPOP
+		c.Type = ChartPieOfPie
+	case 0x101B: // SCATTER
+		c.Type = ChartScatter
+		if flag(4)&0x1 != 0 {
+			c.Type = ChartBubble
+		}
+	case 0x103E: // RADAR
+		c.Type = ChartRadar
+	case 0x1040: // RADARAREA
+		c.Type = ChartFilledRadar
+	case 0x103F: // SURF
+		c.Type = ChartSurface
+	}
+}
+
+// linkObjects matches the OBJ records to the shapes of the drawing that
+// have client data, in order, and places embedded charts and objects.
+func (w *WorkSheet) linkObjects() {
+	k := 0
+	for _, sp := range w.shapes {
+		if !sp.clientData {
+			continue
+		}
+		if k >= len(w.objs) {
+			break
+		}
+		obj := w.objs[k]
+		k++
+		if obj.chart > 0 {
+			c := &w.Charts[obj.chart-1]
+			c.Name = sp.name
+			c.Anchor = sp.anchor
+		}
+		if obj.embedded {
+			if e, ok := w.embeddedObject(obj); ok {
+				e.Name, e.Anchor = sp.name, sp.anchor
+				w.EmbeddedObjects = append(w.EmbeddedObjects, e)
+			}
+		}
+	}
+}
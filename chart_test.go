@@ -0,0 +1,84 @@
+package xls
+
+import "testing"
+
+func seriesText(s string) []byte {
+	return biffRecord(0x100D, cat(le16(0), []byte{byte(len(s)), 0}, []byte(s)))
+}
+
+func chartRecords(typ uint16, flags ...uint16) []byte {
+	begin, end := biffRecord(0x1033, nil), biffRecord(0x1034, nil)
+	area := cat([]byte{0x3B}, le16(0, 1, 4, 1, 1))
+	return cat(
+		biffRecord(0x809, cat(le16(0x600, 0x20), make([]byte, 12))),
+		biffRecord(0x1002, make([]byte, 16)),
+		begin,
+		biffRecord(0x1003, make([]byte, 12)),
+		begin,
+		seriesText("Sales"),
+		biffRecord(0x1051, cat([]byte{1, 2}, le16(0, 0, uint16(len(area))), area)),
+		end,
+		biffRecord(typ, le16(flags...)),
+		biffRecord(0x1025, make([]byte, 32)),
+		begin,
+		biffRecord(0x1027, le16(linkTitle, 0, 0)),
+		seriesText("Revenue"),
+		end,
+		biffRecord(0x1025, make([]byte, 32)),
+		begin,
+		biffRecord(0x1027, le16(linkValueAxis, 0, 0)),
+		seriesText("Amount"),
+		end,
+		end,
+		biffRecord(0x0A, nil),
+	)
+}
+
+func TestEmbeddedChart(t *testing.T) {
+	drawing := escher(0xF, 1, escherDgContainer,
+		escher(0xF, 0, escherSpgrContainer,
+			escher(0xF, 0, escherSpContainer, escher(1, 0, escherSp, le32(1024), le32(5))),
+			escher(0xF, 0, escherSpContainer,
+				escher(2, 201, escherSp, le32(1025), le32(0xA00)),
+				escher(0, 0, escherClientAnchor, le16(2, 3, 0, 1, 0, 8, 0, 15, 0)),
+				escher(0, 0, escherClientData),
+			),
+		),
+	)
+	s := sheetFromRecords(t, nil,
+		biffRecord(0x0EC, drawing),
+		biffRecord(0x05D, cat(le16(0x15, 0x12, 5, 1, 0x6011), make([]byte, 12), le32(0))),
+		chartRecords(0x1017, 0, 150, 0x3),
+		numberRecord(0, 0, 42),
+	)
+	if len(s.Charts) != 1 {
+		t.Fatalf("got %d charts, want 1", len(s.Charts))
+	}
+	c := s.Charts[0]
+	if c.Type != ChartBar || !c.Stacked || c.Percent || c.ThreeD {
+		t.Errorf("chart type = %v stacked %v percent %v 3D %v", c.Type, c.Stacked, c.Percent, c.ThreeD)
+	}
+	if c.Title != "Revenue" || c.ValueAxisTitle != "Amount" || c.CategoryAxisTitle != "" {
+		t.Errorf("titles = %q %q %q", c.Title, c.ValueAxisTitle, c.CategoryAxisTitle)
+	}
+	if len(c.Series) != 1 || c.Series[0].Name != "Sales" || c.Series[0].Values != "#REF!$B$2:$B$5" {
+		t.Errorf("series = %+v", c.Series)
+	}
+	if want := (Anchor{FirstCol: 3, FirstRow: 1, LastCol: 8, LastRow: 15}); c.Anchor != want {
+		t.Errorf("anchor = %+v, want %+v", c.Anchor, want)
+	}
+	// The chart EOF does not end the sheet.
+	if s.Row(0) == nil {
+		t.Errorf("cell after chart not read")
+	}
+}
+
+func TestChartSheet(t *testing.T) {
+	s := sheetFromRecords(t, nil, chartRecords(0x1018, 0x2))
+	if len(s.Charts) != 1 {
+		t.Fatalf("got %d charts, want 1", len(s.Charts))
+	}
+	if c := s.Charts[0]; c.Type != ChartLine || c.Stacked || !c.Percent || c.Title != "Revenue" {
+		t.Errorf("chart = %+v", c)
+	}
+}
@@ -1,6 +1,7 @@
 package xls
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math"
 	"strconv"
@@ -104,7 +105,9 @@ func (xf *XfRk) Value(wb *WorkBook) CellValue {
 	format := ""
 	if len(wb.XF) > idx {
 		fNo := wb.XF[idx].formatNo()
-		format = wb.Formats[fNo].str
+		if fo, ok := wb.Formats[fNo]; ok {
+			format = fo.str
+		}
 	}
 	return CellValue{
 		Int:    i,
@@ -210,6 +213,13 @@ func (c *MulBlankCol) Value(wb *WorkBook) CellValue {
 	return CellValue{}
 }
 
+func (c *MulBlankCol) xfIndex(col uint16) uint16 {
+	if i := int(col) - int(c.FirstColB); i >= 0 && i < len(c.Xfs) {
+		return c.Xfs[i]
+	}
+	return 0
+}
+
 var _ contentHandler = &NumberCol{}
 
 type NumberCol struct {
@@ -238,11 +248,18 @@ func (c *NumberCol) String(wb *WorkBook) []string {
 	}
 }
 
+func (c *NumberCol) xfIndex(col uint16) uint16 {
+	return c.Index
+}
+
 func (c *NumberCol) Value(wb *WorkBook) CellValue {
 	fNo := wb.XF[c.Index].formatNo()
-	fo, _ := wb.Formats[fNo]
+	format := ""
+	if fo, ok := wb.Formats[fNo]; ok {
+		format = fo.str
+	}
 	return CellValue{
-		Format: fo.str,
+		Format: format,
 		Float:  c.Float,
 	}
 }
@@ -251,9 +268,14 @@ var _ contentHandler = &FormulaStringCol{}
 
 type FormulaStringCol struct {
 	Col
+	Xf            uint16
 	RenderedValue string
 }
 
+func (c *FormulaStringCol) xfIndex(col uint16) uint16 {
+	return c.Xf
+}
+
 func (c *FormulaStringCol) String(wb *WorkBook) []string {
 	return []string{c.RenderedValue}
 }
@@ -277,13 +299,51 @@ type FormulaCol struct {
 	Bts []byte
 }
 
+func (c *FormulaCol) Row() uint16 {
+	return c.Header.Row()
+}
+
+func (c *FormulaCol) FirstCol() uint16 {
+	return c.Header.FirstCol()
+}
+
+func (c *FormulaCol) LastCol() uint16 {
+	return c.Header.LastCol()
+}
+
+// result returns a cell holding the cached result of the formula, nil for a
+// string result, which the STRING record after the formula holds.
+func (c *FormulaCol) result() contentHandler {
+	b := c.Header.Result
+	if b[6] != 0xFF || b[7] != 0xFF {
+		return &NumberCol{Col: c.Header.Col, Index: c.Header.IndexXf, Float: math.Float64frombits(binary.LittleEndian.Uint64(b[:]))}
+	}
+	switch b[0] {
+	case 1, 2: // Boolean, error.
+		return &BoolErrCol{Col: c.Header.Col, Xf: c.Header.IndexXf, Val: b[2], IsErr: b[0] - 1}
+	case 3: // Empty string.
+		return &FormulaStringCol{Col: c.Header.Col, Xf: c.Header.IndexXf}
+	}
+	return nil
+}
+
 func (c *FormulaCol) String(wb *WorkBook) []string {
-	return []string{"FormulaCol"}
+	if r := c.result(); r != nil {
+		return r.String(wb)
+	}
+	return []string{""}
 }
 func (c *FormulaCol) Value(wb *WorkBook) CellValue {
+	if r := c.result(); r != nil {
+		return r.Value(wb)
+	}
 	return CellValue{}
 }
 
+func (c *FormulaCol) xfIndex(col uint16) uint16 {
+	return c.Header.IndexXf
+}
+
 var _ contentHandler = &RkCol{}
 
 type RkCol struct {
@@ -298,6 +358,10 @@ func (c *RkCol) Value(wb *WorkBook) CellValue {
 	return c.Xfrk.Value(wb)
 }
 
+func (c *RkCol) xfIndex(col uint16) uint16 {
+	return c.Xfrk.Index
+}
+
 var _ contentHandler = &LabelsstCol{}
 
 type LabelsstCol struct {
@@ -315,6 +379,10 @@ func (c *LabelsstCol) Value(wb *WorkBook) CellValue {
 	}
 }
 
+func (c *LabelsstCol) xfIndex(col uint16) uint16 {
+	return c.Xf
+}
+
 var _ contentHandler = &labelCol{}
 
 type labelCol struct {
@@ -345,3 +413,53 @@ func (c *BlankCol) String(wb *WorkBook) []string {
 func (c *BlankCol) Value(wb *WorkBook) CellValue {
 	return CellValue{}
 }
+
+func (c *BlankCol) xfIndex(col uint16) uint16 {
+	return c.Xf
+}
+
+var _ contentHandler = &BoolErrCol{}
+
+// BoolErrCol is a boolean or error constant cell.
+type BoolErrCol struct {
+	Col
+	Xf    uint16
+	Val   byte
+	IsErr byte
+}
+
+var errorText = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+}
+
+func (c *BoolErrCol) text() string {
+	if c.IsErr != 0 {
+		return errorText[c.Val]
+	}
+	if c.Val != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (c *BoolErrCol) String(wb *WorkBook) []string {
+	return []string{c.text()}
+}
+
+func (c *BoolErrCol) xfIndex(col uint16) uint16 {
+	return c.Xf
+}
+
+func (c *BoolErrCol) Value(wb *WorkBook) CellValue {
+	v := CellValue{Text: c.text()}
+	if c.IsErr == 0 {
+		v.Int = int64(c.Val)
+	}
+	return v
+}
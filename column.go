@@ -0,0 +1,66 @@
+package xls
+
+// maxDigitWidth is the pixel width of the widest digit in the default
+// font, 7 for 10pt Arial and 11pt Calibri.
+const maxDigitWidth = 7
+
+type colInfo struct {
+	First uint16
+	Last  uint16
+	Width uint16 // In 1/256 of a character.
+	XF    uint16
+	Flags uint16
+	_     uint16
+}
+
+// Column is the format of a worksheet column.
+type Column struct {
+	// Width is in characters of the maximum digit width of the default
+	// font, including cell padding.
+	Width        float64
+	Pixels       int
+	Hidden       bool
+	CustomWidth  bool
+	OutlineLevel byte
+	Collapsed    bool
+	// XF is the format of empty cells in the column.
+	XF uint16
+}
+
+// Column returns the format of column i (zero-based).
+func (w *WorkSheet) Column(i int) Column {
+	for _, c := range w.colInfos {
+		if int(c.First) > i || int(c.Last) < i {
+			continue
+		}
+		return Column{
+			Width:        float64(c.Width) / 256,
+			Pixels:       (int(c.Width)*maxDigitWidth + 128) / 256,
+			Hidden:       c.Flags&0x1 != 0,
+			CustomWidth:  c.Flags&0x2 != 0,
+			OutlineLevel: byte(c.Flags >> 8 & 0x7),
+			Collapsed:    c.Flags&0x1000 != 0,
+			XF:           c.XF,
+		}
+	}
+	return w.DefaultColumn()
+}
+
+// DefaultColumn returns the format of columns without column information.
+func (w *WorkSheet) DefaultColumn() Column {
+	if w.standardWidth != 0 {
+		return Column{
+			Width:  float64(w.standardWidth) / 256,
+			Pixels: (int(w.standardWidth)*maxDigitWidth + 128) / 256,
+			XF:     defaultXF,
+		}
+	}
+	// DEFCOLWIDTH excludes the padding of two pixels each side and one
+	// pixel of gridline.
+	px := int(w.defColWidth)*maxDigitWidth + 5
+	return Column{
+		Width:  float64(px) / maxDigitWidth,
+		Pixels: px,
+		XF:     defaultXF,
+	}
+}
@@ -0,0 +1,599 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// CondType is the kind of a conditional formatting rule.
+type CondType byte
+
+const (
+	// CondCellValue compares the cell value with the rule formulas.
+	CondCellValue CondType = 1
+	// CondFormula applies when the rule formula is true.
+	CondFormula CondType = 2
+)
+
+// DiffFont is the font part of a differential style. Nil and zero fields
+// keep the font of the cell.
+type DiffFont struct {
+	Name       string
+	Size       float64 // In points.
+	Bold       *bool
+	Italic     *bool
+	Strikeout  *bool
+	Underline  *Underline
+	Script     *Script
+	ColorIndex *uint16
+}
+
+// DiffStyle is the formatting a conditional format applies on top of the
+// style of a cell. Nil fields keep the cell style.
+type DiffStyle struct {
+	// Format is a number format index and FormatCode the code of a format
+	// defined by the rule itself.
+	Format     *uint16
+	FormatCode string
+
+	Font *DiffFont
+
+	Left   *Border
+	Right  *Border
+	Top    *Border
+	Bottom *Border
+
+	Fill *Fill
+
+	Locked *bool
+	Hidden *bool
+}
+
+// CondRule is a rule of a conditional format.
+type CondRule struct {
+	Type     CondType
+	Operator Operator // For CondCellValue rules.
+	Formula1 string
+	Formula2 string
+	Style    DiffStyle
+
+	rgce1, rgce2 []byte
+}
+
+// ConditionalFormat is a set of rules applied to cell ranges. Only the
+// first rule that is true formats a cell.
+type ConditionalFormat struct {
+	Ranges []CellRange
+	Rules  []CondRule
+}
+
+// base is the cell relative references of the rules are relative to.
+func (c *ConditionalFormat) base() cellRef {
+	if len(c.Ranges) == 0 {
+		return cellRef{}
+	}
+	return cellRef{int(c.Ranges[0].FirstRowB), int(c.Ranges[0].FristColB)}
+}
+
+// parseCondFmt reads a CONDFMT record. The CF records of its rules follow.
+func parseCondFmt(bts []byte) ConditionalFormat {
+	buf := bytes.NewReader(bts)
+	var head struct {
+		Count uint16
+		Flags uint16
+		Bound CellRange
+	}
+	binary.Read(buf, binary.LittleEndian, &head)
+	return ConditionalFormat{Ranges: readRanges(buf)}
+}
+
+// parseCF reads a CF record.
+func (w *WorkSheet) parseCF(bts []byte, base cellRef) CondRule {
+	if len(bts) < 6 {
+		return CondRule{}
+	}
+	r := CondRule{Type: CondType(bts[0])}
+	if r.Type == CondCellValue {
+		r.Operator = Operator(bts[1])
+	}
+	cce1 := int(binary.LittleEndian.Uint16(bts[2:]))
+	cce2 := int(binary.LittleEndian.Uint16(bts[4:]))
+	if cce1+cce2 > len(bts)-6 {
+		return r
+	}
+	end := len(bts) - cce1 - cce2
+	r.Style = parseDXFN(bts[6:end])
+	r.rgce1 = bts[end : end+cce1]
+	r.rgce2 = bts[end+cce1:]
+	if cce1 > 0 {
+		r.Formula1, _ = formulaText(r.rgce1, nil, base, w.wb)
+	}
+	if cce2 > 0 {
+		r.Formula2, _ = formulaText(r.rgce2, nil, base, w.wb)
+	}
+	return r
+}
+
+// DXFN flags.
+const (
+	dxfnLockedNinch = 1 << 8
+	dxfnHiddenNinch = 1 << 9
+	dxfnLeftNinch   = 1 << 10
+	dxfnRightNinch  = 1 << 11
+	dxfnTopNinch    = 1 << 12
+	dxfnBottomNinch = 1 << 13
+	dxfnNum         = 1 << 25
+	dxfnFont        = 1 << 26
+	dxfnAlign       = 1 << 27
+	dxfnBorder      = 1 << 28
+	dxfnPattern     = 1 << 29
+	dxfnProtection  = 1 << 30
+)
+
+// parseDXFN reads the differential formatting of a CF record. Its blocks
+// follow the flags in a fixed order, each only when present.
+func parseDXFN(b []byte) DiffStyle {
+	var s DiffStyle
+	if len(b) < 6 {
+		return s
+	}
+	flags := binary.LittleEndian.Uint32(b)
+	userFormat := b[4]&0x1 != 0
+	b = b[6:]
+	if flags&dxfnNum != 0 {
+		if userFormat {
+			if len(b) < 4 {
+				return s
+			}
+			n := int(binary.LittleEndian.Uint16(b))
+			if n < 2 || n > len(b) {
+				return s
+			}
+			p := &formulaParser{b: b[:n], pos: 2}
+			s.FormatCode = p.str(int(p.u16()))
+			b = b[n:]
+		} else {
+			if len(b) < 2 {
+				return s
+			}
+			idx := uint16(b[1])
+			s.Format = &idx
+			b = b[2:]
+		}
+	}
+	if flags&dxfnFont != 0 {
+		if len(b) < 118 {
+			return s
+		}
+		s.Font = parseDXFFont(b[:118])
+		b = b[118:]
+	}
+	if flags&dxfnAlign != 0 {
+		if len(b) < 8 {
+			return s
+		}
+		b = b[8:]
+	}
+	if flags&dxfnBorder != 0 {
+		if len(b) < 8 {
+			return s
+		}
+		line := binary.LittleEndian.Uint32(b)
+		color := binary.LittleEndian.Uint32(b[4:])
+		border := func(ninch uint32, bd Border) *Border {
+			if flags&ninch != 0 {
+				return nil
+			}
+			return &bd
+		}
+		s.Left = border(dxfnLeftNinch, Border{BorderStyle(line & 0xF), uint16(line >> 16 & 0x7F)})
+		s.Right = border(dxfnRightNinch, Border{BorderStyle(line >> 4 & 0xF), uint16(line >> 23 & 0x7F)})
+		s.Top = border(dxfnTopNinch, Border{BorderStyle(line >> 8 & 0xF), uint16(color & 0x7F)})
+		s.Bottom = border(dxfnBottomNinch, Border{BorderStyle(line >> 12 & 0xF), uint16(color >> 7 & 0x7F)})
+		b = b[8:]
+	}
+	if flags&dxfnPattern != 0 {
+		if len(b) < 4 {
+			return s
+		}
+		v := binary.LittleEndian.Uint32(b)
+		s.Fill = &Fill{
+			Pattern:         byte(v >> 10 & 0x3F),
+			ForegroundColor: uint16(v >> 16 & 0x7F),
+			BackgroundColor: uint16(v >> 23 & 0x7F),
+		}
+		b = b[4:]
+	}
+	if flags&dxfnProtection != 0 && len(b) >= 2 {
+		if flags&dxfnLockedNinch == 0 {
+			locked := b[0]&0x1 != 0
+			s.Locked = &locked
+		}
+		if flags&dxfnHiddenNinch == 0 {
+			hidden := b[0]&0x2 != 0
+			s.Hidden = &hidden
+		}
+	}
+	return s
+}
+
+// parseDXFFont reads the 118 byte font block of a DXFN.
+func parseDXFFont(b []byte) *DiffFont {
+	f := new(DiffFont)
+	if cch := int(b[0]); cch > 0 {
+		p := &formulaParser{b: b[:64], pos: 1}
+		if name := p.str(cch); p.err == nil {
+			f.Name = name
+		}
+	}
+	u32 := func(off int) uint32 { return binary.LittleEndian.Uint32(b[off:]) }
+	if h := u32(64); h != math.MaxUint32 {
+		f.Size = float64(h) / 20
+	}
+	options, unchanged := u32(68), u32(88)
+	if unchanged&0x02 == 0 {
+		italic := options&0x02 != 0
+		f.Italic = &italic
+	}
+	if unchanged&0x80 == 0 {
+		strike := options&0x80 != 0
+		f.Strikeout = &strike
+	}
+	if u32(100) == 0 {
+		bold := binary.LittleEndian.Uint16(b[72:]) >= 600
+		f.Bold = &bold
+	}
+	if u32(92) == 0 {
+		script := Script(binary.LittleEndian.Uint16(b[74:]))
+		f.Script = &script
+	}
+	if u32(96) == 0 {
+		underline := Underline(b[76])
+		f.Underline = &underline
+	}
+	if c := u32(80); c != math.MaxUint32 {
+		color := uint16(c)
+		f.ColorIndex = &color
+	}
+	return f
+}
+
+// cfValue is a value of the conditional format evaluator.
+type cfValue struct {
+	num   float64
+	str   string
+	isStr bool
+}
+
+// ConditionalStyle returns the style of the first true rule of the
+// conditional formats covering a zero-based cell. Rules with formulas
+// beyond constants, references to the same sheet, arithmetic and
+// comparisons are skipped.
+func (w *WorkSheet) ConditionalStyle(row, col int) (DiffStyle, bool) {
+	for i := range w.ConditionalFormats {
+		cf := &w.ConditionalFormats[i]
+		in := false
+		for j := range cf.Ranges {
+			if cf.Ranges[j].contains(row, col) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			continue
+		}
+		e := &cfEval{w: w, base: cf.base(), cell: cellRef{row, col}}
+		for _, r := range cf.Rules {
+			if e.match(&r) {
+				return r.Style, true
+			}
+		}
+	}
+	return DiffStyle{}, false
+}
+
+// EffectiveStyle returns the style and font of a zero-based cell with its
+// conditional format applied.
+func (w *WorkSheet) EffectiveStyle(row, col int) (Style, Font, bool) {
+	r := w.Row(row)
+	if r == nil {
+		return Style{}, Font{}, false
+	}
+	st, ok := r.Style(col)
+	if !ok {
+		return Style{}, Font{}, false
+	}
+	font, _ := w.wb.Font(st.Font)
+	d, ok := w.ConditionalStyle(row, col)
+	if !ok {
+		return st, font, true
+	}
+	if d.Format != nil {
+		st.Format = *d.Format
+	}
+	for _, b := range []struct {
+		d *Border
+		s *Border
+	}{{d.Left, &st.Left}, {d.Right, &st.Right}, {d.Top, &st.Top}, {d.Bottom, &st.Bottom}} {
+		if b.d != nil {
+			*b.s = *b.d
+		}
+	}
+	if d.Fill != nil {
+		st.Fill = *d.Fill
+	}
+	if d.Locked != nil {
+		st.Locked = *d.Locked
+	}
+	if d.Hidden != nil {
+		st.Hidden = *d.Hidden
+	}
+	if f := d.Font; f != nil {
+		if f.Name != "" {
+			font.Name = f.Name
+		}
+		if f.Size != 0 {
+			font.Size = f.Size
+		}
+		if f.Bold != nil {
+			font.Bold = *f.Bold
+			font.Weight = 400
+			if font.Bold {
+				font.Weight = 700
+			}
+		}
+		if f.Italic != nil {
+			font.Italic = *f.Italic
+		}
+		if f.Strikeout != nil {
+			font.Strikeout = *f.Strikeout
+		}
+		if f.Underline != nil {
+			font.Underline = *f.Underline
+		}
+		if f.Script != nil {
+			font.Script = *f.Script
+		}
+		if f.ColorIndex != nil {
+			font.ColorIndex = *f.ColorIndex
+			font.Color = w.wb.Color(font.ColorIndex)
+		}
+	}
+	return st, font, true
+}
+
+// cfEval evaluates rule formulas for a cell. References with relative rows
+// or columns move with the cell from the top left cell of the ranges.
+type cfEval struct {
+	w    *WorkSheet
+	base cellRef
+	cell cellRef
+}
+
+func (e *cfEval) match(r *CondRule) bool {
+	v1, ok := e.eval(r.rgce1)
+	if !ok {
+		return false
+	}
+	if r.Type == CondFormula {
+		return !v1.isStr && v1.num != 0
+	}
+	cur, ok := e.value(e.cell.row, e.cell.col)
+	if !ok {
+		return false
+	}
+	switch r.Operator {
+	case OperatorBetween, OperatorNotBetween:
+		v2, ok := e.eval(r.rgce2)
+		if !ok {
+			return false
+		}
+		lo, hi := v1, v2
+		if cfCompare(lo, hi) > 0 {
+			lo, hi = hi, lo
+		}
+		in := cfCompare(cur, lo) >= 0 && cfCompare(cur, hi) <= 0
+		return in == (r.Operator == OperatorBetween)
+	}
+	return cfOperator(r.Operator, cfCompare(cur, v1))
+}
+
+// cfOperator applies a comparison operator to the result of cfCompare.
+func cfOperator(op Operator, c int) bool {
+	switch op {
+	case OperatorEqual:
+		return c == 0
+	case OperatorNotEqual:
+		return c != 0
+	case OperatorGreater:
+		return c > 0
+	case OperatorLess:
+		return c < 0
+	case OperatorGreaterOrEqual:
+		return c >= 0
+	case OperatorLessOrEqual:
+		return c <= 0
+	}
+	return false
+}
+
+// cfCompare orders numbers before strings and strings without case.
+func cfCompare(a, b cfValue) int {
+	switch {
+	case a.isStr && b.isStr:
+		return strings.Compare(strings.ToLower(a.str), strings.ToLower(b.str))
+	case a.isStr:
+		return 1
+	case b.isStr:
+		return -1
+	case a.num < b.num:
+		return -1
+	case a.num > b.num:
+		return 1
+	}
+	return 0
+}
+
+// value returns the value of a zero-based cell of the sheet. Empty cells
+// are zero. Formula cells are not evaluable before their string result.
+func (e *cfEval) value(row, col int) (cfValue, bool) {
+	r := e.w.Row(row)
+	if r == nil {
+		return cfValue{}, true
+	}
+	ch := r.cell(col)
+	if f, ok := ch.(*FormulaCol); ok {
+		if ch = f.result(); ch == nil {
+			return cfValue{}, false
+		}
+	}
+	switch c := ch.(type) {
+	case nil, *BlankCol, *MulBlankCol:
+		return cfValue{}, true
+	case *NumberCol:
+		return cfValue{num: c.Float}, true
+	case *RkCol:
+		return cfValue{num: rkFloat(c.Xfrk.Rk)}, true
+	case *MulrkCol:
+		if i := col - int(c.FirstCol()); i >= 0 && i < len(c.Xfrks) {
+			return cfValue{num: rkFloat(c.Xfrks[i].Rk)}, true
+		}
+		return cfValue{}, true
+	case *BoolErrCol:
+		if c.IsErr == 0 {
+			return cfValue{num: float64(c.Val)}, true
+		}
+		return cfValue{str: c.text(), isStr: true}, true
+	default:
+		s := c.String(e.w.wb)
+		if i := col - int(c.FirstCol()); i >= 0 && i < len(s) {
+			return cfValue{str: s[i], isStr: true}, true
+		}
+		return cfValue{}, true
+	}
+}
+
+func rkFloat(rk RK) float64 {
+	i, f, isFloat := rk.number()
+	if isFloat {
+		return f
+	}
+	return float64(i)
+}
+
+// comparisonOps maps comparison tokens to operators.
+var comparisonOps = map[byte]Operator{
+	0x09: OperatorLess,
+	0x0A: OperatorLessOrEqual,
+	0x0B: OperatorEqual,
+	0x0C: OperatorGreaterOrEqual,
+	0x0D: OperatorGreater,
+	0x0E: OperatorNotEqual,
+}
+
+// eval evaluates formula tokens.
+func (e *cfEval) eval(rgce []byte) (cfValue, bool) {
+	p := &formulaParser{b: rgce}
+	var stack []cfValue
+	pop2 := func() (a, b cfValue, ok bool) {
+		if len(stack) < 2 {
+			return a, b, false
+		}
+		a, b = stack[len(stack)-2], stack[len(stack)-1]
+		stack = stack[:len(stack)-2]
+		return a, b, true
+	}
+	for p.pos < len(p.b) && p.err == nil {
+		ptg := p.u8()
+		if ptg >= 0x20 {
+			ptg = ptg&0x1F | 0x20
+		}
+		switch ptg {
+		case 0x03, 0x04, 0x05, 0x06: // Arithmetic.
+			a, b, ok := pop2()
+			if !ok || a.isStr || b.isStr {
+				return cfValue{}, false
+			}
+			switch ptg {
+			case 0x03:
+				a.num += b.num
+			case 0x04:
+				a.num -= b.num
+			case 0x05:
+				a.num *= b.num
+			case 0x06:
+				if b.num == 0 {
+					return cfValue{}, false
+				}
+				a.num /= b.num
+			}
+			stack = append(stack, a)
+		case 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E: // Comparison.
+			a, b, ok := pop2()
+			if !ok {
+				return cfValue{}, false
+			}
+			op := comparisonOps[ptg]
+			v := cfValue{}
+			if cfOperator(op, cfCompare(a, b)) {
+				v.num = 1
+			}
+			stack = append(stack, v)
+		case 0x13: // ptgUminus
+			if len(stack) == 0 || stack[len(stack)-1].isStr {
+				return cfValue{}, false
+			}
+			stack[len(stack)-1].num = -stack[len(stack)-1].num
+		case 0x12, 0x15: // ptgUplus, ptgParen
+		case 0x17: // ptgStr
+			stack = append(stack, cfValue{str: p.str(int(p.u8())), isStr: true})
+		case 0x19: // ptgAttr
+			if flags := p.u8(); flags&^0x41 != 0 {
+				return cfValue{}, false
+			}
+			p.u16()
+		case 0x1D: // ptgBool
+			stack = append(stack, cfValue{num: float64(p.u8())})
+		case 0x1E: // ptgInt
+			stack = append(stack, cfValue{num: float64(p.u16())})
+		case 0x1F: // ptgNum
+			stack = append(stack, cfValue{num: p.f64()})
+		case 0x24, 0x2C: // ptgRef, ptgRefN
+			row, col := p.u16(), p.u16()
+			r, c := e.refCell(row, col, ptg == 0x2C)
+			v, ok := e.value(r, c)
+			if !ok {
+				return cfValue{}, false
+			}
+			stack = append(stack, v)
+		default:
+			return cfValue{}, false
+		}
+	}
+	if p.err != nil || len(stack) != 1 {
+		return cfValue{}, false
+	}
+	return stack[0], true
+}
+
+// refCell resolves a reference for the evaluated cell. ptgRefN tokens hold
+// offsets from the cell itself.
+func (e *cfEval) refCell(row, col uint16, offset bool) (int, int) {
+	rowRel, colRel := col&0x8000 != 0, col&0x4000 != 0
+	r, c := int(row), int(col&0x3FFF)
+	switch {
+	case offset && rowRel:
+		r = e.cell.row + int(int16(row))
+	case rowRel:
+		r += e.cell.row - e.base.row
+	}
+	switch {
+	case offset && colRel:
+		c = e.cell.col + int(int8(col))
+	case colRel:
+		c += e.cell.col - e.base.col
+	}
+	return r, c
+}
@@ -0,0 +1,143 @@
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func numberRecord(row, col uint16, f float64) []byte {
+	b := cat(le16(row, col, 0), make([]byte, 8))
+	binary.LittleEndian.PutUint64(b[6:], math.Float64bits(f))
+	return biffRecord(0x203, b)
+}
+
+func cfRecord(ct, op byte, dxfn, f1, f2 []byte) []byte {
+	return biffRecord(0x1B1, cat([]byte{ct, op}, le16(uint16(len(f1)), uint16(len(f2))), dxfn, f1, f2))
+}
+
+func TestConditionalFormat(t *testing.T) {
+	font := make([]byte, 118)
+	copy(font[64:], le32(math.MaxUint32))
+	copy(font[72:], le16(700))
+	copy(font[80:], le32(10))
+	copy(font[88:], le32(0x82))
+	copy(font[92:], le32(1))
+	copy(font[96:], le32(1))
+	redBold := cat(le32(dxfnFont|dxfnPattern|0x3FF), le16(0), font, le32(1<<10|10<<16|11<<23))
+	bottom := cat(le32(dxfnBorder|dxfnLeftNinch|dxfnRightNinch|dxfnTopNinch), le16(0), le32(1<<12), le32(12<<7))
+	blank := cat(le32(0), le16(0))
+
+	wb := &WorkBook{
+		Formats: make(map[uint16]*Format),
+		XF:      []XF{&xf8{}},
+		Fonts:   []Font{{Name: "Arial", Size: 10, Weight: 400}},
+	}
+	s := sheetFromRecords(t, wb,
+		numberRecord(0, 0, 150),
+		numberRecord(1, 0, 50),
+		biffRecord(0x204, cat(le16(1, 1, 0), mustXLString("X", 2))),
+		numberRecord(2, 0, 3),
+		numberRecord(3, 0, 10),
+		biffRecord(0x1B0, cat(le16(3, 0, 0, 9, 0, 0), le16(1, 0, 9, 0, 0))),
+		cfRecord(1, byte(OperatorGreater), redBold, cat([]byte{0x1E}, le16(100)), nil),
+		cfRecord(2, 0, bottom, cat([]byte{0x24}, le16(0, 0x8001), ptgStr("x"), []byte{0x0B}), nil),
+		cfRecord(1, byte(OperatorBetween), blank, cat([]byte{0x1E}, le16(5)), cat([]byte{0x1E}, le16(1))),
+		// Data validations follow the conditional formats of a sheet.
+		biffRecord(0x1B2, make([]byte, 18)),
+		biffRecord(0x1BE, dvRecord(0x1, "", cat([]byte{0x1E}, le16(1)), nil, 0, 0, 0, 0)),
+	)
+	if len(s.ConditionalFormats) != 1 || len(s.ConditionalFormats[0].Rules) != 3 {
+		t.Fatalf("conditional formats = %+v", s.ConditionalFormats)
+	}
+	if len(s.DataValidations) != 1 {
+		t.Errorf("got %d data validations", len(s.DataValidations))
+	}
+	rules := s.ConditionalFormats[0].Rules
+	if rules[0].Formula1 != "100" || rules[1].Type != CondFormula || rules[1].Formula1 != `$B1="x"` {
+		t.Errorf("formulas = %q, %q", rules[0].Formula1, rules[1].Formula1)
+	}
+	if rules[2].Operator != OperatorBetween || rules[2].Formula2 != "1" {
+		t.Errorf("between rule = %+v", rules[2])
+	}
+	d := rules[0].Style
+	if d.Font == nil || d.Font.Bold == nil || !*d.Font.Bold || d.Font.Italic != nil || d.Font.Size != 0 {
+		t.Errorf("font = %+v", d.Font)
+	}
+	if d.Fill == nil || *d.Fill != (Fill{Pattern: 1, ForegroundColor: 10, BackgroundColor: 11}) {
+		t.Errorf("fill = %+v", d.Fill)
+	}
+	if d := rules[1].Style; d.Left != nil || d.Bottom == nil || *d.Bottom != (Border{BorderThin, 12}) {
+		t.Errorf("borders = %+v", d)
+	}
+
+	st, f, ok := s.EffectiveStyle(0, 0)
+	if !ok || st.Fill.Pattern != 1 || !f.Bold || f.ColorIndex != 10 || f.Color != wb.Color(10) || f.Name != "Arial" {
+		t.Errorf("A1 = %+v, %+v", st, f)
+	}
+	if d, ok := s.ConditionalStyle(1, 0); !ok || d.Bottom == nil {
+		t.Errorf("A2 = %+v, %v", d, ok)
+	}
+	if d, ok := s.ConditionalStyle(2, 0); !ok || d.Font != nil || d.Bottom != nil {
+		t.Errorf("A3 = %+v, %v", d, ok)
+	}
+	if _, ok := s.ConditionalStyle(3, 0); ok {
+		t.Error("A4 should not match")
+	}
+	if _, ok := s.ConditionalStyle(0, 1); ok {
+		t.Error("B1 is outside the ranges")
+	}
+	if st, f, _ := s.EffectiveStyle(3, 0); st.Fill.Pattern != 0 || f.Bold {
+		t.Errorf("A4 = %+v, %+v", st, f)
+	}
+}
+
+// cachedFormula builds a FORMULA record with a cached result and the
+// formula =1.
+func cachedFormula(row, col uint16, result []byte) []byte {
+	rgce := cat([]byte{0x1E}, le16(1))
+	return biffRecord(0x06, cat(le16(row, col, 0), result, le16(0), le32(0), le16(uint16(len(rgce))), rgce))
+}
+
+func TestConditionalFormatFormulaCells(t *testing.T) {
+	num := func(f float64) []byte {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, math.Float64bits(f))
+		return b
+	}
+	bottom := cat(le32(dxfnBorder|dxfnLeftNinch|dxfnRightNinch|dxfnTopNinch), le16(0), le32(1<<12), le32(12<<7))
+	blank := cat(le32(0), le16(0))
+	wb := &WorkBook{Formats: make(map[uint16]*Format), XF: []XF{&xf8{}}}
+	s := sheetFromRecords(t, wb,
+		cachedFormula(0, 0, num(150)),
+		cachedFormula(1, 0, num(50)),
+		cachedFormula(2, 0, le16(1, 1, 0, 0xFFFF)), // TRUE
+		cachedFormula(3, 0, le16(0, 0, 0, 0xFFFF)), // String without STRING record.
+		cachedFormula(4, 0, le16(0, 0, 0, 0xFFFF)),
+		biffRecord(0x207, mustXLString("abc", 2)),
+		biffRecord(0x1B0, cat(le16(2, 0, 0, 9, 0, 0), le16(1, 0, 9, 0, 0))),
+		cfRecord(1, byte(OperatorGreater), blank, cat([]byte{0x1E}, le16(100)), nil),
+		cfRecord(1, byte(OperatorLess), bottom, cat([]byte{0x1E}, le16(100)), nil),
+	)
+	if got := s.Row(1).Col(0); got != "50" {
+		t.Errorf("A2 = %q", got)
+	}
+	if got := s.Row(4).Col(0); got != "abc" {
+		t.Errorf("A5 = %q", got)
+	}
+	if d, ok := s.ConditionalStyle(0, 0); !ok || d.Bottom != nil {
+		t.Errorf("A1 = %+v, %v", d, ok)
+	}
+	if d, ok := s.ConditionalStyle(1, 0); !ok || d.Bottom == nil {
+		t.Errorf("A2 = %+v, %v", d, ok)
+	}
+	if d, ok := s.ConditionalStyle(2, 0); !ok || d.Bottom == nil {
+		t.Errorf("A3 = %+v, %v", d, ok)
+	}
+	if _, ok := s.ConditionalStyle(3, 0); ok {
+		t.Error("A4 has no cached result")
+	}
+	if d, ok := s.ConditionalStyle(4, 0); !ok || d.Bottom != nil {
+		t.Errorf("A5 = %+v, %v", d, ok)
+	}
+}
@@ -0,0 +1,391 @@
+package xls
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"unicode/utf16"
+)
+
+var (
+	// ErrPasswordRequired is returned when a workbook is encrypted and no
+	// password, or only the default password, was given.
+	ErrPasswordRequired = errors.New("xls: workbook is password protected")
+	// ErrWrongPassword is returned when the given password does not match.
+	ErrWrongPassword = errors.New("xls: wrong password")
+)
+
+// defaultPassword is used by Excel when a workbook is only write protected.
+const defaultPassword = "VelvetSweatshop"
+
+// rc4Block is the number of stream bytes encrypted with one RC4 key.
+const rc4Block = 1024
+
+// decrypter decrypts record data at a given workbook stream position.
+type decrypter interface {
+	decrypt(data []byte, pos int64, recSize int)
+}
+
+// noDecrypt lists records which are never encrypted.
+var noDecrypt = map[uint16]bool{
+	0x809: true, // BOF
+	0x02F: true, // FILEPASS
+	0x0E1: true, // INTERFACEHDR
+	0x194: true, // USREXCL
+	0x195: true, // FILELOCK
+	0x196: true, // RRDINFO
+	0x138: true, // RRDHEAD
+}
+
+// parseFilePass returns a decrypter for the FILEPASS record body.
+func parseFilePass(body []byte, password string) (decrypter, error) {
+	if len(body) < 6 {
+		return nil, fmt.Errorf("FILEPASS record too short")
+	}
+	if password == "" {
+		password = defaultPassword
+	}
+	var d decrypter
+	var err error
+	switch typ := binary.LittleEndian.Uint16(body); typ {
+	case 0:
+		d, err = newXORDecrypter(body[2:], password)
+	case 1:
+		major := binary.LittleEndian.Uint16(body[2:])
+		minor := binary.LittleEndian.Uint16(body[4:])
+		switch {
+		case major == 1 && minor == 1:
+			d, err = newRC4Decrypter(body[6:], password)
+		case major >= 2 && major <= 4 && minor == 2:
+			d, err = newCryptoAPIDecrypter(body[6:], password)
+		default:
+			return nil, fmt.Errorf("unsupported RC4 encryption version %d.%d", major, minor)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption type %d", typ)
+	}
+	if err == ErrWrongPassword && password == defaultPassword {
+		err = ErrPasswordRequired
+	}
+	return d, err
+}
+
+// decryptStream decrypts all records in data after the FILEPASS record
+// which ends at pos. Record headers are left as is.
+func decryptStream(data []byte, pos int64, d decrypter) {
+	for pos+4 <= int64(len(data)) {
+		id := binary.LittleEndian.Uint16(data[pos:])
+		size := int64(binary.LittleEndian.Uint16(data[pos+2:]))
+		start := pos + 4
+		end := start + size
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		pos = end
+		if noDecrypt[id] {
+			continue
+		}
+		if id == 0x85 && end-start >= 4 {
+			// The sheet stream position of BOUNDSHEET is not encrypted.
+			d.decrypt(data[start+4:end], start+4, int(size))
+			continue
+		}
+		d.decrypt(data[start:end], start, int(size))
+	}
+}
+
+// xorKey holds the method 1 XOR obfuscation array.
+type xorKey [16]byte
+
+var xorInitialCode = [15]uint16{
+	0xE1F0, 0x1D0F, 0xCC9C, 0x84C0, 0x110C, 0x0E10, 0xF1CE,
+	0x313E, 0x1872, 0xE139, 0xD40F, 0x84F9, 0x280C, 0xA96A, 0x4EC3,
+}
+
+var xorMatrix = [105]uint16{
+	0xAEFC, 0x4DD9, 0x9BB2, 0x2745, 0x4E8A, 0x9D14, 0x2A09,
+	0x7B61, 0xF6C2, 0xFDA5, 0xEB6B, 0xC6F7, 0x9DCF, 0x2BBF,
+	0x4563, 0x8AC6, 0x05AD, 0x0B5A, 0x16B4, 0x2D68, 0x5AD0,
+	0x0375, 0x06EA, 0x0DD4, 0x1BA8, 0x3750, 0x6EA0, 0xDD40,
+	0xD849, 0xA0B3, 0x5147, 0xA28E, 0x553D, 0xAA7A, 0x44D5,
+	0x6F45, 0xDE8A, 0xAD35, 0x4A4B, 0x9496, 0x390D, 0x721A,
+	0xEB23, 0xC667, 0x9CEF, 0x29FF, 0x53FE, 0xA7FC, 0x5FD9,
+	0x47D3, 0x8FA6, 0x0F6D, 0x1EDA, 0x3DB4, 0x7B68, 0xF6D0,
+	0xB861, 0x60E3, 0xC1C6, 0x93AD, 0x377B, 0x6EF6, 0xDDEC,
+	0x45A0, 0x8B40, 0x06A1, 0x0D42, 0x1A84, 0x3508, 0x6A10,
+	0xAA51, 0x4483, 0x8906, 0x022D, 0x045A, 0x08B4, 0x1168,
+	0x76B4, 0xED68, 0xCAF1, 0x85C3, 0x1BA7, 0x374E, 0x6E9C,
+	0x3730, 0x6E60, 0xDCC0, 0xA9A1, 0x4363, 0x86C6, 0x1DAD,
+	0x3331, 0x6662, 0xCCC4, 0x89A9, 0x0373, 0x06E6, 0x0DCC,
+	0x1021, 0x2042, 0x4084, 0x8108, 0x1231, 0x2462, 0x48C4,
+}
+
+var xorPad = [15]byte{
+	0xBB, 0xFF, 0xFF, 0xBA, 0xFF, 0xFF, 0xB9, 0x80,
+	0x00, 0xBE, 0x0F, 0x00, 0xBF, 0x0F, 0x00,
+}
+
+// xorPassword converts a password to the single byte form used by the
+// XOR method, at most 15 characters.
+func xorPassword(password string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(password)) {
+		if len(b) == 15 {
+			break
+		}
+		if c&0xFF != 0 {
+			b = append(b, byte(c))
+		} else {
+			b = append(b, byte(c>>8))
+		}
+	}
+	return b
+}
+
+// passwordVerifier returns the 16-bit password hash of the XOR method. The
+// same hash is stored by the PASSWORD record of protected sheets.
+func passwordVerifier(password string) uint16 {
+	pw := xorPassword(password)
+	var v uint16
+	all := append([]byte{byte(len(pw))}, pw...)
+	for i := len(all) - 1; i >= 0; i-- {
+		v = ((v>>14)&1 | (v<<1)&0x7FFF) ^ uint16(all[i])
+	}
+	return v ^ 0xCE4B
+}
+
+func xorKeyCode(pw []byte) uint16 {
+	if len(pw) == 0 {
+		return 0
+	}
+	key := xorInitialCode[len(pw)-1]
+	el := len(xorMatrix) - 1
+	for i := len(pw) - 1; i >= 0; i-- {
+		c := pw[i]
+		for bit := 0; bit < 7; bit++ {
+			if c&0x40 != 0 {
+				key ^= xorMatrix[el]
+			}
+			c <<= 1
+			el--
+		}
+	}
+	return key
+}
+
+func xorRor(a, b byte) byte {
+	v := a ^ b
+	return v>>1 | v<<7
+}
+
+func newXORKey(password string) *xorKey {
+	pw := xorPassword(password)
+	code := xorKeyCode(pw)
+	hi, lo := byte(code>>8), byte(code)
+	k := new(xorKey)
+	i := len(pw)
+	if i%2 == 1 {
+		k[i] = xorRor(xorPad[0], hi)
+		i--
+		k[i] = xorRor(pw[len(pw)-1], lo)
+	}
+	for i > 0 {
+		i--
+		k[i] = xorRor(pw[i], hi)
+		i--
+		k[i] = xorRor(pw[i], lo)
+	}
+	i = 15
+	for p := 15 - len(pw); p > 0; {
+		k[i] = xorRor(xorPad[p], hi)
+		i--
+		p--
+		k[i] = xorRor(xorPad[p], lo)
+		i--
+		p--
+	}
+	return k
+}
+
+func newXORDecrypter(body []byte, password string) (decrypter, error) {
+	if len(body) < 4 {
+		return nil, fmt.Errorf("XOR FILEPASS record too short")
+	}
+	verifier := binary.LittleEndian.Uint16(body[2:])
+	if passwordVerifier(password) != verifier {
+		return nil, ErrWrongPassword
+	}
+	return newXORKey(password), nil
+}
+
+func (k *xorKey) decrypt(data []byte, pos int64, recSize int) {
+	for i := range data {
+		v := data[i] ^ k[(pos+int64(recSize)+int64(i))%16]
+		data[i] = v>>5 | v<<3
+	}
+}
+
+func (k *xorKey) encrypt(data []byte, pos int64, recSize int) {
+	for i := range data {
+		v := data[i]<<5 | data[i]>>3
+		data[i] = v ^ k[(pos+int64(recSize)+int64(i))%16]
+	}
+}
+
+// rc4Stream decrypts with a keystream keyed per 1024 byte block of the
+// workbook stream.
+type rc4Stream struct {
+	blockKey func(block uint32) []byte
+
+	c     *rc4.Cipher
+	block int64
+	at    int64
+}
+
+func (s *rc4Stream) decrypt(data []byte, pos int64, recSize int) {
+	for len(data) > 0 {
+		block := pos / rc4Block
+		if s.c == nil || block != s.block || s.at > pos {
+			s.c, _ = rc4.NewCipher(s.blockKey(uint32(block)))
+			s.block, s.at = block, block*rc4Block
+		}
+		if skip := pos - s.at; skip > 0 {
+			s.c.XORKeyStream(make([]byte, skip), make([]byte, skip))
+			s.at = pos
+		}
+		n := int64(len(data))
+		if rest := (block+1)*rc4Block - pos; n > rest {
+			n = rest
+		}
+		s.c.XORKeyStream(data[:n], data[:n])
+		data = data[n:]
+		pos += n
+		s.at = pos
+	}
+}
+
+// verify checks the encrypted verifier against its encrypted hash.
+func (s *rc4Stream) verify(verifier, verifierHash []byte, h hash.Hash) bool {
+	c, _ := rc4.NewCipher(s.blockKey(0))
+	v := make([]byte, len(verifier))
+	c.XORKeyStream(v, verifier)
+	vh := make([]byte, len(verifierHash))
+	c.XORKeyStream(vh, verifierHash)
+	h.Write(v)
+	return bytes.Equal(h.Sum(nil), vh[:h.Size()])
+}
+
+func utf16Password(password string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(password)) {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	return b
+}
+
+// rc4Key derives the block keys of standard RC4 encryption.
+func rc4Key(salt []byte, password string) *rc4Stream {
+	h0 := md5.Sum(utf16Password(password))
+	buf := make([]byte, 0, 16*21)
+	for i := 0; i < 16; i++ {
+		buf = append(buf, h0[:5]...)
+		buf = append(buf, salt...)
+	}
+	h1 := md5.Sum(buf)
+	return &rc4Stream{blockKey: func(block uint32) []byte {
+		b := make([]byte, 9)
+		copy(b, h1[:5])
+		binary.LittleEndian.PutUint32(b[5:], block)
+		k := md5.Sum(b)
+		return k[:]
+	}}
+}
+
+// cryptoAPIKey derives the block keys of CryptoAPI RC4 encryption.
+func cryptoAPIKey(salt []byte, password string, keyBits uint32) *rc4Stream {
+	h0 := sha1.Sum(append(append([]byte{}, salt...), utf16Password(password)...))
+	return &rc4Stream{blockKey: func(block uint32) []byte {
+		b := make([]byte, 24)
+		copy(b, h0[:])
+		binary.LittleEndian.PutUint32(b[20:], block)
+		h := sha1.Sum(b)
+		if keyBits == 40 {
+			// 40-bit keys are padded with zeros to 128 bits.
+			k := make([]byte, 16)
+			copy(k, h[:5])
+			return k
+		}
+		return h[:keyBits/8]
+	}}
+}
+
+func newRC4Decrypter(body []byte, password string) (decrypter, error) {
+	if len(body) < 48 {
+		return nil, fmt.Errorf("RC4 FILEPASS record too short")
+	}
+	s := rc4Key(body[:16], password)
+	if !s.verify(body[16:32], body[32:48], md5.New()) {
+		return nil, ErrWrongPassword
+	}
+	return s, nil
+}
+
+func newCryptoAPIDecrypter(body []byte, password string) (decrypter, error) {
+	if len(body) < 8 {
+		return nil, fmt.Errorf("CryptoAPI FILEPASS record too short")
+	}
+	hsize := binary.LittleEndian.Uint32(body[4:])
+	if uint64(hsize)+8 > uint64(len(body)) || hsize < 32 {
+		return nil, fmt.Errorf("invalid CryptoAPI header size %d", hsize)
+	}
+	header := body[8 : 8+hsize]
+	keyBits := binary.LittleEndian.Uint32(header[16:])
+	if keyBits == 0 {
+		keyBits = 40
+	}
+	if keyBits < 40 || keyBits > 128 || keyBits%8 != 0 {
+		return nil, fmt.Errorf("invalid CryptoAPI key size %d", keyBits)
+	}
+	v := body[8+hsize:]
+	if len(v) < 4+16+16+4+20 || binary.LittleEndian.Uint32(v) != 16 {
+		return nil, fmt.Errorf("invalid CryptoAPI verifier")
+	}
+	s := cryptoAPIKey(v[4:20], password, keyBits)
+	if !s.verify(v[20:36], v[40:60], sha1.New()) {
+		return nil, ErrWrongPassword
+	}
+	return s, nil
+}
+
+// decrypt replaces the workbook stream with its decrypted content. pos is
+// the stream position just after the FILEPASS record.
+func (w *WorkBook) decrypt(filepass []byte, pos int64) error {
+	d, err := parseFilePass(filepass, w.password)
+	if err != nil {
+		return err
+	}
+	if _, err := w.rs.Seek(0, 0); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(w.rs)
+	if err != nil && len(data) == 0 {
+		return err
+	}
+	if int64(len(data)) < pos {
+		return fmt.Errorf("workbook stream truncated")
+	}
+	decryptStream(data, pos, d)
+	rs := bytes.NewReader(data)
+	if _, err := rs.Seek(pos, 0); err != nil {
+		return err
+	}
+	w.rs = rs
+	w.Encrypted = true
+	return nil
+}
@@ -0,0 +1,226 @@
+package xls
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// xorEncrypter encrypts with the XOR method when used as a decrypter.
+type xorEncrypter struct{ *xorKey }
+
+func (e xorEncrypter) decrypt(data []byte, pos int64, recSize int) {
+	e.encrypt(data, pos, recSize)
+}
+
+// encryptedWorkbook inserts a FILEPASS record after the first BOF of the
+// workbook written by w and encrypts all records following it.
+func encryptedWorkbook(t *testing.T, w *WorkBookWriter, filepass []byte, enc decrypter) []byte {
+	t.Helper()
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, start := insertRecords(stream, biffRecord(0x2F, filepass))
+	decryptStream(stream, int64(start), enc)
+	return packWorkbook(t, stream)
+}
+
+func testWorkbook() *WorkBookWriter {
+	w := NewWriter()
+	s := w.AddSheet("Secret")
+	for i := 0; i < 300; i++ {
+		s.SetString(i, 0, fmt.Sprintf("value %d", i), 0)
+		s.SetNumber(i, 1, float64(i)/4, 0)
+	}
+	w.AddSheet("Other").SetString(1, 1, "hidden", 0)
+	return w
+}
+
+func checkDecrypted(t *testing.T, wb *WorkBook) {
+	t.Helper()
+	if !wb.Encrypted || wb.NumSheets() != 2 {
+		t.Fatalf("got encrypted %t with %d sheets", wb.Encrypted, wb.NumSheets())
+	}
+	s, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "Secret" || s.MaxRow != 299 {
+		t.Fatalf("got sheet %q with max row %d", s.Name, s.MaxRow)
+	}
+	for i := 0; i < 300; i++ {
+		if got := s.Row(i).Col(0); got != fmt.Sprintf("value %d", i) {
+			t.Fatalf("row %d: got %q", i, got)
+		}
+		if got := s.Row(i).Value(1).Float; got != float64(i)/4 {
+			t.Fatalf("row %d: got %v", i, got)
+		}
+	}
+	s, err = wb.GetSheet(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Row(1).Col(1); got != "hidden" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func xorFilePass(password string) ([]byte, decrypter) {
+	body := make([]byte, 6)
+	binary.LittleEndian.PutUint16(body[2:], xorKeyCode(xorPassword(password)))
+	binary.LittleEndian.PutUint16(body[4:], passwordVerifier(password))
+	return body, xorEncrypter{newXORKey(password)}
+}
+
+func rc4FilePass(password string) ([]byte, decrypter) {
+	salt := []byte("0123456789abcdef")
+	verifier := []byte("fedcba9876543210")
+	s := rc4Key(salt, password)
+	h := md5.Sum(verifier)
+	enc := append(append([]byte{}, verifier...), h[:]...)
+	c, _ := rc4.NewCipher(s.blockKey(0))
+	c.XORKeyStream(enc, enc)
+
+	body := []byte{1, 0, 1, 0, 1, 0}
+	body = append(body, salt...)
+	return append(body, enc...), s
+}
+
+func cryptoAPIFilePass(password string, keyBits uint32) ([]byte, decrypter) {
+	salt := []byte("saltsaltsaltsalt")
+	verifier := []byte("verifierverifier")
+	s := cryptoAPIKey(salt, password, keyBits)
+	h := sha1.Sum(verifier)
+	enc := append(append([]byte{}, verifier...), h[:]...)
+	c, _ := rc4.NewCipher(s.blockKey(0))
+	c.XORKeyStream(enc, enc)
+
+	header := make([]byte, 32)
+	binary.LittleEndian.PutUint32(header[8:], 0x6801)  // RC4
+	binary.LittleEndian.PutUint32(header[12:], 0x8004) // SHA1
+	binary.LittleEndian.PutUint32(header[16:], keyBits)
+	binary.LittleEndian.PutUint32(header[20:], 1)
+
+	body := []byte{1, 0, 4, 0, 2, 0, 4, 0, 0, 0}
+	body = append(body, le32(uint32(len(header)))...)
+	body = append(body, header...)
+	body = append(body, le32(16)...)
+	body = append(body, salt...)
+	body = append(body, enc[:16]...)
+	body = append(body, le32(20)...)
+	return append(body, enc[16:]...), s
+}
+
+func TestDecrypt(t *testing.T) {
+	const password = "Finance2021"
+	for _, tc := range []struct {
+		name  string
+		build func() ([]byte, decrypter)
+	}{
+		{"xor", func() ([]byte, decrypter) { return xorFilePass(password) }},
+		{"rc4", func() ([]byte, decrypter) { return rc4FilePass(password) }},
+		{"cryptoapi40", func() ([]byte, decrypter) { return cryptoAPIFilePass(password, 40) }},
+		{"cryptoapi128", func() ([]byte, decrypter) { return cryptoAPIFilePass(password, 128) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filepass, enc := tc.build()
+			file := encryptedWorkbook(t, testWorkbook(), filepass, enc)
+
+			wb, err := OpenReaderWithPassword(bytes.NewReader(file), "utf-8", password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkDecrypted(t, wb)
+
+			if _, err := OpenReader(bytes.NewReader(file), "utf-8"); err != ErrPasswordRequired {
+				t.Fatalf("got %v, want ErrPasswordRequired", err)
+			}
+			if _, err := OpenReaderWithPassword(bytes.NewReader(file), "utf-8", "guess"); err != ErrWrongPassword {
+				t.Fatalf("got %v, want ErrWrongPassword", err)
+			}
+		})
+	}
+}
+
+func TestDecryptDefaultPassword(t *testing.T) {
+	filepass, enc := rc4FilePass(defaultPassword)
+	file := encryptedWorkbook(t, testWorkbook(), filepass, enc)
+	wb, err := OpenReader(bytes.NewReader(file), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDecrypted(t, wb)
+}
+
+func TestDecryptKnownAnswer(t *testing.T) {
+	// Vectors for the password "password" with salt 00..0F and verifier
+	// 10..1F, computed with a separate implementation of MS-OFFCRYPTO.
+	// 0x83AF is the well known Excel hash of "password".
+	unhex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	if v := passwordVerifier("password"); v != 0x83AF {
+		t.Errorf("verifier = %04X", v)
+	}
+	if k := xorKeyCode(xorPassword("password")); k != 0x147A {
+		t.Errorf("key = %04X", k)
+	}
+	if k := newXORKey("password"); !bytes.Equal(k[:], unhex("05ba84b386bd0438e0f5c257c2f5e14a")) {
+		t.Errorf("XOR array = %x", k[:])
+	}
+
+	salt := unhex("000102030405060708090a0b0c0d0e0f")
+	header := make([]byte, 32)
+	binary.LittleEndian.PutUint32(header[8:], 0x6801)  // RC4
+	binary.LittleEndian.PutUint32(header[12:], 0x8004) // SHA1
+	cryptoAPI := func(keyBits uint32, verifier string) []byte {
+		binary.LittleEndian.PutUint32(header[16:], keyBits)
+		v := unhex(verifier)
+		return cat([]byte{1, 0, 4, 0, 2, 0, 4, 0, 0, 0}, le32(32), header, le32(16), salt, v[:16], le32(20), v[16:])
+	}
+	for _, tc := range []struct {
+		name      string
+		filepass  []byte
+		pos       int64
+		encrypted string
+		plain     string
+	}{
+		{"xor", le16(0, 0x147A, 0x83AF), 100, "ef70e9d62df1ccdaeed82cc4a9b50a97", "Known plaintext!"},
+		{"rc4", cat([]byte{1, 0, 1, 0, 1, 0}, salt, unhex("0467c1fcedec5020bd3253fa0f8a18ff0cc6e5eaa1001489dfd93c5e29024295")),
+			1016, "db6f2fd3d5d48d55420f72b90a3cecd1", "block boundary!!"},
+		{"cryptoapi40", cryptoAPI(40, "e05d9fc926cb77ebb1008350c850b341961108c7e587d830a7feb753a3440c341b4dda12"),
+			1016, "eb4d3f1caa31dddeb1593f60e09c64fd", "block boundary!!"},
+		{"cryptoapi128", cryptoAPI(128, "c1ecc68427ba1587c8093c435b6a903df736420c5dd715fa1763eab17ae37281c6ac57ab"),
+			1016, "52b41baaa164092a2ac6ea75a58d8a00", "block boundary!!"},
+	} {
+		d, err := parseFilePass(tc.filepass, "password")
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		data := unhex(tc.encrypted)
+		d.decrypt(data, tc.pos, len(data))
+		if string(data) != tc.plain {
+			t.Errorf("%s: decrypted %q", tc.name, data)
+		}
+		if _, err := parseFilePass(tc.filepass, "Password"); err != ErrWrongPassword {
+			t.Errorf("%s: got %v, want ErrWrongPassword", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package xls
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io/ioutil"
+	"unicode/utf16"
+)
+
+// Office Drawing record types.
+const (
+	escherDggContainer    = 0xF000
+	escherBStoreContainer = 0xF001
+	escherDgContainer     = 0xF002
+	escherSpgrContainer   = 0xF003
+	escherSpContainer     = 0xF004
+	escherBSE             = 0xF007
+	escherSp              = 0xF00A
+	escherOPT             = 0xF00B
+	escherClientTextbox   = 0xF00D
+	escherChildAnchor     = 0xF00F
+	escherClientAnchor    = 0xF010
+	escherClientData      = 0xF011
+)
+
+// Shape properties.
+const (
+	propBlip        = 0x104
+	propBlipName    = 0x105
+	propName        = 0x380
+	propDescription = 0x381
+)
+
+// escherRecord is an Office Drawing record. Containers hold further
+// records in their data.
+type escherRecord struct {
+	ver  uint16
+	inst uint16
+	typ  uint16
+	data []byte
+}
+
+// readEscher reads the records in b. A truncated last record keeps the
+// data present.
+func readEscher(b []byte) []escherRecord {
+	var recs []escherRecord
+	for len(b) >= 8 {
+		verInst := binary.LittleEndian.Uint16(b)
+		r := escherRecord{
+			ver:  verInst & 0xF,
+			inst: verInst >> 4,
+			typ:  binary.LittleEndian.Uint16(b[2:]),
+		}
+		n := binary.LittleEndian.Uint32(b[4:])
+		b = b[8:]
+		if uint64(n) > uint64(len(b)) {
+			n = uint32(len(b))
+		}
+		r.data, b = b[:n], b[n:]
+		recs = append(recs, r)
+	}
+	return recs
+}
+
+func (r escherRecord) children() []escherRecord {
+	if r.ver != 0xF {
+		return nil
+	}
+	return readEscher(r.data)
+}
+
+// Anchor places a drawing object on the cells of a sheet. DX offsets are
+// in 1/1024 of the column width and DY offsets in 1/256 of the row height.
+type Anchor struct {
+	FirstCol int
+	FirstDX  int
+	FirstRow int
+	FirstDY  int
+	LastCol  int
+	LastDX   int
+	LastRow  int
+	LastDY   int
+}
+
+func parseAnchor(b []byte) (Anchor, bool) {
+	if len(b) < 18 {
+		return Anchor{}, false
+	}
+	v := func(i int) int { return int(binary.LittleEndian.Uint16(b[2+2*i:])) }
+	return Anchor{
+		FirstCol: v(0), FirstDX: v(1), FirstRow: v(2), FirstDY: v(3),
+		LastCol: v(4), LastDX: v(5), LastRow: v(6), LastDY: v(7),
+	}, true
+}
+
+// Picture is an image placed on a sheet.
+type Picture struct {
+	Name        string
+	Description string
+	// MIME is the media type of Data and Ext its usual file extension.
+	MIME   string
+	Ext    string
+	Data   []byte
+	Anchor Anchor
+}
+
+// blip is a picture of the drawing group.
+type blip struct {
+	mime string
+	ext  string
+	data []byte
+}
+
+type blipType struct {
+	mime     string
+	ext      string
+	metafile bool
+}
+
+var blipTypes = map[uint16]blipType{
+	0xF01A: {"image/x-emf", "emf", true},
+	0xF01B: {"image/x-wmf", "wmf", true},
+	0xF01C: {"image/x-pict", "pict", true},
+	0xF01D: {"image/jpeg", "jpeg", false},
+	0xF01E: {"image/png", "png", false},
+	0xF01F: {"image/bmp", "bmp", false},
+	0xF029: {"image/tiff", "tiff", false},
+	0xF02A: {"image/jpeg", "jpeg", false},
+}
+
+// parseDrawingGroup reads the pictures of the MSODRAWINGGROUP records.
+func (w *WorkBook) parseDrawingGroup() {
+	data := w.drawingGroup
+	w.drawingGroup = nil
+	for _, dgg := range readEscher(data) {
+		if dgg.typ != escherDggContainer {
+			continue
+		}
+		for _, store := range dgg.children() {
+			if store.typ != escherBStoreContainer {
+				continue
+			}
+			for _, bse := range store.children() {
+				if bse.typ != escherBSE {
+					continue
+				}
+				w.blips = append(w.blips, parseBSE(bse.data))
+			}
+		}
+	}
+}
+
+// parseBSE reads the picture embedded in a BSE record, nil if there is
+// none.
+func parseBSE(b []byte) *blip {
+	// Picture types, UID, tag, size, references, offset and name length.
+	if len(b) < 36 || 36+int(b[33]) > len(b) {
+		return nil
+	}
+	b = b[36+int(b[33]):]
+	recs := readEscher(b)
+	if len(recs) == 0 {
+		return nil
+	}
+	return parseBlip(recs[0])
+}
+
+func parseBlip(r escherRecord) *blip {
+	t, ok := blipTypes[r.typ]
+	if !ok {
+		return nil
+	}
+	// An odd instance has a second UID.
+	skip := 16
+	if r.inst&0x1 != 0 {
+		skip += 16
+	}
+	if t.metafile {
+		// Metafile header: size, bounds, size in EMUs, saved size,
+		// compression and filter.
+		skip += 34
+	} else {
+		skip++ // Tag.
+	}
+	if len(r.data) < skip {
+		return nil
+	}
+	b := &blip{mime: t.mime, ext: t.ext, data: r.data[skip:]}
+	switch {
+	case t.metafile && r.data[skip-2] == 0:
+		zr, err := zlib.NewReader(bytes.NewReader(b.data))
+		if err != nil {
+			return nil
+		}
+		if b.data, err = ioutil.ReadAll(zr); err != nil {
+			return nil
+		}
+	case r.typ == 0xF01F:
+		b.data = dibToBMP(b.data)
+	}
+	return b
+}
+
+// dibToBMP adds a file header to a device independent bitmap.
+func dibToBMP(dib []byte) []byte {
+	if len(dib) < 16 {
+		return dib
+	}
+	headerSize := binary.LittleEndian.Uint32(dib)
+	offset := 14 + headerSize
+	if headerSize >= 40 {
+		bits := binary.LittleEndian.Uint16(dib[14:])
+		colors := binary.LittleEndian.Uint32(dib[32:])
+		if colors == 0 && bits <= 8 {
+			colors = 1 << bits
+		}
+		offset += 4 * colors
+		if headerSize == 40 && binary.LittleEndian.Uint32(dib[16:]) == 3 {
+			offset += 12 // BI_BITFIELDS masks.
+		}
+	} else if headerSize == 12 {
+		// OS/2 core header with RGB triples.
+		if bits := binary.LittleEndian.Uint16(dib[10:]); bits <= 8 {
+			offset += 3 << bits
+		}
+	}
+	bmp := make([]byte, 14, 14+len(dib))
+	bmp[0], bmp[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(bmp[2:], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(bmp[10:], offset)
+	return append(bmp, dib...)
+}
+
+// shape is a shape of a sheet drawing.
+type shape struct {
+	id          uint32
+	typ         uint16
+	anchor      Anchor
+	blip        int // One-based index of the picture, 0 for none.
+	name        string
+	description string
+	clientData  bool // An OBJ record belongs to the shape.
+}
+
+// parseDrawing reads the shapes of the MSODRAWING records of the sheet.
+func (w *WorkSheet) parseDrawing() {
+	data := w.drawing
+	w.drawing = nil
+	for _, dg := range readEscher(data) {
+		if dg.typ != escherDgContainer {
+			continue
+		}
+		for _, r := range dg.children() {
+			if r.typ == escherSpgrContainer {
+				w.parseShapeGroup(r, nil)
+			}
+		}
+	}
+	for _, sp := range w.shapes {
+		if sp.blip == 0 || sp.blip > len(w.wb.blips) || w.wb.blips[sp.blip-1] == nil {
+			continue
+		}
+		b := w.wb.blips[sp.blip-1]
+		w.Pictures = append(w.Pictures, Picture{
+			Name:        sp.name,
+			Description: sp.description,
+			MIME:        b.mime,
+			Ext:         b.ext,
+			Data:        b.data,
+			Anchor:      sp.anchor,
+		})
+	}
+}
+
+// parseShapeGroup reads a group of shapes. The first shape describes the
+// group itself, and shapes in groups take the anchor of the outermost group
+// on the sheet. The top group of the sheet has no anchor.
+func (w *WorkSheet) parseShapeGroup(group escherRecord, anchor *Anchor) {
+	for i, r := range group.children() {
+		switch r.typ {
+		case escherSpContainer:
+			if i == 0 && anchor == nil {
+				continue
+			}
+			sp := parseShape(r)
+			if anchor != nil {
+				sp.anchor = *anchor
+			}
+			w.shapes = append(w.shapes, sp)
+		case escherSpgrContainer:
+			sub := anchor
+			if kids := r.children(); sub == nil && len(kids) > 0 {
+				a := parseShape(kids[0]).anchor
+				sub = &a
+			}
+			w.parseShapeGroup(r, sub)
+		}
+	}
+}
+
+func parseShape(sp escherRecord) shape {
+	var s shape
+	for _, r := range sp.children() {
+		switch r.typ {
+		case escherSp:
+			s.typ = r.inst
+			if len(r.data) >= 4 {
+				s.id = binary.LittleEndian.Uint32(r.data)
+			}
+		case escherOPT:
+			props, complex := parseOPT(r)
+			s.blip = int(props[propBlip])
+			s.name = utf16String(complex[propName])
+			if s.name == "" {
+				s.name = utf16String(complex[propBlipName])
+			}
+			s.description = utf16String(complex[propDescription])
+		case escherClientAnchor:
+			s.anchor, _ = parseAnchor(r.data)
+		case escherClientData:
+			s.clientData = true
+		}
+	}
+	return s
+}
+
+// parseOPT reads the properties of an OPT record. Complex properties have
+// their data stored after the property table.
+func parseOPT(r escherRecord) (map[uint16]uint32, map[uint16][]byte) {
+	props := make(map[uint16]uint32)
+	complex := make(map[uint16][]byte)
+	n := int(r.inst)
+	if 6*n > len(r.data) {
+		n = len(r.data) / 6
+	}
+	extra := r.data[6*n:]
+	for i := 0; i < n; i++ {
+		id := binary.LittleEndian.Uint16(r.data[6*i:])
+		v := binary.LittleEndian.Uint32(r.data[6*i+2:])
+		props[id&0x3FFF] = v
+		if id&0x8000 != 0 {
+			k := int(v)
+			if k > len(extra) || k < 0 {
+				k = len(extra)
+			}
+			complex[id&0x3FFF], extra = extra[:k], extra[k:]
+		}
+	}
+	return props, complex
+}
+
+// utf16String decodes a NUL terminated UTF-16 string.
+func utf16String(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		u = append(u, c)
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,113 @@
+package xls
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// escher builds an Office Drawing record.
+func escher(ver, inst, typ uint16, data ...[]byte) []byte {
+	body := cat(data...)
+	return cat(le16(ver|inst<<4, typ), le32(uint32(len(body))), body)
+}
+
+func bse(blip []byte) []byte {
+	head := make([]byte, 36)
+	binary.LittleEndian.PutUint32(head[20:], uint32(len(blip)))
+	return escher(2, 0, escherBSE, head, blip)
+}
+
+func utf16z(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s + "\x00")) {
+		b = append(b, le16(c)...)
+	}
+	return b
+}
+
+func pictureShape(id uint32, blip uint32, name string, anchor ...uint16) []byte {
+	nameData := utf16z(name)
+	opt := cat(le16(propBlip|0x4000), le32(blip), le16(propName|0x8000), le32(uint32(len(nameData))), nameData)
+	return escher(0xF, 0, escherSpContainer,
+		escher(2, 75, escherSp, le32(id), le32(0xA00)),
+		escher(3, 2, escherOPT, opt),
+		escher(0, 0, escherClientAnchor, le16(2), le16(anchor...)),
+		escher(0, 0, escherClientData),
+	)
+}
+
+func TestPictures(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+	emf := []byte(" EMF metafile data")
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(emf)
+	zw.Close()
+	metaHeader := cat(le32(uint32(len(emf))), make([]byte, 24), le32(uint32(z.Len())), []byte{0, 0xFE})
+	dib := cat(le32(40), le32(1), le32(1), le16(1, 24), le32(0), le32(4), make([]byte, 16), []byte{1, 2, 3, 0})
+
+	wb := &WorkBook{Formats: make(map[uint16]*Format)}
+	wb.drawingGroup = escher(0xF, 0, escherDggContainer,
+		escher(0, 0, 0xF006, make([]byte, 16)),
+		escher(0xF, 3, escherBStoreContainer,
+			bse(escher(0, 0x6E0, 0xF01E, make([]byte, 17), png)),
+			bse(escher(0, 0x3D4, 0xF01A, make([]byte, 16), metaHeader, z.Bytes())),
+			bse(escher(0, 0x7A8, 0xF01F, make([]byte, 17), dib)),
+		),
+	)
+	wb.parseDrawingGroup()
+	if len(wb.blips) != 3 {
+		t.Fatalf("got %d blips, want 3", len(wb.blips))
+	}
+
+	drawing := escher(0xF, 1, escherDgContainer,
+		escher(0, 1, 0xF008, le32(3), le32(1026)),
+		escher(0xF, 0, escherSpgrContainer,
+			escher(0xF, 0, escherSpContainer, escher(1, 0, escherSp, le32(1024), le32(5))),
+			pictureShape(1025, 1, "Photo", 1, 0, 2, 0, 3, 512, 6, 128),
+			// A group with the metafile and the bitmap.
+			escher(0xF, 0, escherSpgrContainer,
+				escher(0xF, 0, escherSpContainer,
+					escher(1, 0, escherSp, le32(1026), le32(0x201)),
+					escher(0, 0, escherClientAnchor, le16(0, 4, 0, 10, 0, 8, 0, 20, 0)),
+				),
+				pictureShape(1027, 2, "Logo"),
+				pictureShape(1028, 3, "Icon"),
+			),
+		),
+	)
+	s := sheetFromRecords(t, wb,
+		biffRecord(0x0EC, drawing[:40]),
+		biffRecord(0x0EC, drawing[40:]),
+		biffRecord(0x1B2, make([]byte, 18)),
+	)
+	if len(s.Pictures) != 3 {
+		t.Fatalf("got %d pictures, want 3", len(s.Pictures))
+	}
+	p := s.Pictures[0]
+	if p.Name != "Photo" || p.MIME != "image/png" || p.Ext != "png" || !bytes.Equal(p.Data, png) {
+		t.Errorf("picture 0 = %+v", p)
+	}
+	if want := (Anchor{FirstCol: 1, FirstRow: 2, LastCol: 3, LastDX: 512, LastRow: 6, LastDY: 128}); p.Anchor != want {
+		t.Errorf("anchor = %+v, want %+v", p.Anchor, want)
+	}
+	p = s.Pictures[1]
+	if p.Name != "Logo" || p.MIME != "image/x-emf" || !bytes.Equal(p.Data, emf) || p.Anchor.FirstCol != 4 || p.Anchor.LastRow != 20 {
+		t.Errorf("picture 1 = %+v", p)
+	}
+	p = s.Pictures[2]
+	if p.Ext != "bmp" || len(p.Data) != 14+len(dib) || string(p.Data[:2]) != "BM" || binary.LittleEndian.Uint32(p.Data[10:]) != 54 {
+		t.Errorf("picture 2 = %+v", p)
+	}
+}
+
+func TestParseBSEName(t *testing.T) {
+	b := make([]byte, 40)
+	b[33] = 0xFF // The name runs past the record.
+	if parseBSE(b) != nil {
+		t.Error("expected no picture")
+	}
+}
@@ -0,0 +1,197 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+// EmbeddedObject is an OLE object embedded in a sheet, such as a document
+// or a packaged file.
+type EmbeddedObject struct {
+	// ProgID identifies the kind of object, such as "Word.Document.8",
+	// "AcroExch.Document" or "Package".
+	ProgID string
+	// FileName is the name of a packaged file, empty for other objects.
+	FileName string
+	// Data is the packaged file, the contents or native data of the
+	// object, or else its whole storage as a compound file.
+	Data   []byte
+	Name   string
+	Anchor Anchor
+}
+
+// parsePictFmla reads the storage of an embedded object from the
+// ftPictFmla subrecord of an OBJ record. Controls keep their data in the
+// Ctls stream instead.
+func (w *WorkSheet) parsePictFmla(obj *object, data []byte, pictFlags uint16) {
+	if len(data) < 2 {
+		return
+	}
+	n := int(binary.LittleEndian.Uint16(data))
+	if n > len(data)-2 {
+		return
+	}
+	fmla, rest := data[2:2+n], data[2+n:]
+	// The formula is followed by the class name of the object.
+	if len(fmla) >= 6 {
+		i := 6 + int(binary.LittleEndian.Uint16(fmla)&0x7FFF)
+		if i+3 < len(fmla) && fmla[i] == 0x03 {
+			obj.class, _ = w.wb.getString(bytes.NewReader(fmla[i+3:]), uint16(fmla[i+1]))
+		}
+	}
+	if pictFlags&0x30 == 0 && len(rest) >= 4 { // Not fCtl or fPrstm.
+		obj.storage = binary.LittleEndian.Uint32(rest)
+		obj.embedded = true
+	}
+}
+
+// embeddedObject reads the MBD storage of an embedded object.
+func (w *WorkSheet) embeddedObject(obj object) (EmbeddedObject, bool) {
+	wb := w.wb
+	if wb.root == nil {
+		return EmbeddedObject{}, false
+	}
+	st := wb.root.Child(fmt.Sprintf("MBD%08X", obj.storage))
+	if st == nil || !st.IsStorage() {
+		return EmbeddedObject{}, false
+	}
+	e := EmbeddedObject{ProgID: obj.class}
+	if c := st.Child("\x01CompObj"); c != nil {
+		if b, err := wb.ole.ReadEntry(c); err == nil {
+			if id := compObjProgID(b, wb.Codepage); id != "" {
+				e.ProgID = id
+			}
+		}
+	}
+	for _, name := range []string{"\x01Ole10Native", "CONTENTS", "Package"} {
+		c := st.Child(name)
+		if c == nil || !c.IsStream() {
+			continue
+		}
+		b, err := wb.ole.ReadEntry(c)
+		if err != nil {
+			return EmbeddedObject{}, false
+		}
+		e.Data = b
+		if name == "\x01Ole10Native" {
+			e.FileName, e.Data = ole10Native(b, strings.EqualFold(e.ProgID, "Package"))
+		}
+		return e, true
+	}
+	cfb := ole2.NewWriter()
+	if err := copyStorage(wb.ole, st, cfb.Root); err != nil {
+		return EmbeddedObject{}, false
+	}
+	var buf bytes.Buffer
+	if _, err := cfb.WriteTo(&buf); err != nil {
+		return EmbeddedObject{}, false
+	}
+	e.Data = buf.Bytes()
+	return e, true
+}
+
+// copyStorage copies the storages and streams under src to dst.
+func copyStorage(o *ole2.Ole, src *ole2.Entry, dst *ole2.Storage) error {
+	dst.CLSID = src.CLSID()
+	dst.Created, dst.Modified = src.Created(), src.Modified()
+	for _, c := range src.Children {
+		switch {
+		case c.IsStorage():
+			if err := copyStorage(o, c, dst.AddStorage(c.Name())); err != nil {
+				return err
+			}
+		case c.IsStream():
+			b, err := o.ReadEntry(c)
+			if err != nil {
+				return err
+			}
+			dst.AddStream(c.Name(), b)
+		}
+	}
+	return nil
+}
+
+// compObjProgID returns the ProgID of a CompObj stream, which follows the
+// user type and the clipboard format.
+func compObjProgID(b []byte, codepage uint16) string {
+	if len(b) < 28 {
+		return ""
+	}
+	b = b[28:]
+	next := func() []byte {
+		if len(b) < 4 {
+			b = nil
+			return nil
+		}
+		n := binary.LittleEndian.Uint32(b)
+		b = b[4:]
+		if uint64(n) > uint64(len(b)) {
+			n = uint32(len(b))
+		}
+		s := b[:n]
+		b = b[n:]
+		return s
+	}
+	next() // User type.
+	if len(b) >= 4 {
+		// Standard clipboard formats have an identifier in place of a
+		// name.
+		if m := binary.LittleEndian.Uint32(b); m == 0xFFFFFFFF || m == 0xFFFFFFFE {
+			b = b[4:]
+			if len(b) >= 4 {
+				b = b[4:]
+			}
+		} else {
+			next()
+		}
+	}
+	return ole2.DecodeString(next(), codepage)
+}
+
+// ole10Native unwraps the data of an Ole10Native stream. Packages hold a
+// label, the source path, a temporary path and then the file.
+func ole10Native(b []byte, pkg bool) (string, []byte) {
+	if len(b) < 4 {
+		return "", nil
+	}
+	size := binary.LittleEndian.Uint32(b)
+	b = b[4:]
+	if uint64(size) < uint64(len(b)) {
+		b = b[:size]
+	}
+	if !pkg || len(b) < 2 {
+		return "", b
+	}
+	native := b
+	p := b[2:]
+	cstr := func() (string, bool) {
+		i := bytes.IndexByte(p, 0)
+		if i < 0 {
+			return "", false
+		}
+		s := string(p[:i])
+		p = p[i+1:]
+		return s, true
+	}
+	label, ok1 := cstr()
+	_, ok2 := cstr()
+	if !ok1 || !ok2 || len(p) < 8 {
+		return "", native
+	}
+	n := binary.LittleEndian.Uint32(p[4:])
+	p = p[8:]
+	if uint64(n)+4 > uint64(len(p)) {
+		return "", native
+	}
+	p = p[n:]
+	size = binary.LittleEndian.Uint32(p)
+	p = p[4:]
+	if uint64(size) > uint64(len(p)) {
+		return "", native
+	}
+	return label, p[:size]
+}
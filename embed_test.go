@@ -0,0 +1,108 @@
+package xls
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+func lenPrefixed(s string) []byte {
+	return cat(le32(uint32(len(s)+1)), []byte(s), []byte{0})
+}
+
+func compObj(progID string) []byte {
+	return cat(make([]byte, 28), lenPrefixed("Object"), le32(0xFFFFFFFE), le32(3), lenPrefixed(progID))
+}
+
+// embedObj builds an OBJ record of an embedded object with its class name
+// and storage.
+func embedObj(id uint16, pictFlags uint16, class string, storage uint32) []byte {
+	fmla := cat(le16(5), le32(0), []byte{0x02}, le32(0), []byte{0x03, byte(len(class)), 0, 0}, []byte(class))
+	if len(fmla)%2 != 0 {
+		fmla = append(fmla, 0)
+	}
+	pict := cat(le16(uint16(len(fmla))), fmla, le32(storage))
+	return biffRecord(0x05D, cat(
+		le16(0x15, 0x12, 8, id, 0x6011), make([]byte, 12),
+		le16(0x07, 2, 0xFFFF),
+		le16(0x08, 2, pictFlags),
+		le16(0x09, uint16(len(pict))), pict,
+		le32(0),
+	))
+}
+
+func TestEmbeddedObjects(t *testing.T) {
+	w := NewWriter()
+	w.AddSheet("Sheet1")
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf := []byte("%PDF-1.4 audit report")
+	temp := "C:\\Temp\\report.pdf\x00"
+	pkg := cat(le16(2), []byte("report.pdf\x00C:\\Docs\\report.pdf\x00"), le16(0, 3),
+		le32(uint32(len(temp))), []byte(temp), le32(uint32(len(pdf))), pdf, le16(0))
+	cfb := ole2.NewWriter()
+	cfb.Root.AddStream("Workbook", stream)
+	mbd := cfb.Root.AddStorage("MBD00000001")
+	mbd.AddStream("\x01CompObj", compObj("Package"))
+	mbd.AddStream("\x01Ole10Native", cat(le32(uint32(len(pkg))), pkg))
+	mbd = cfb.Root.AddStorage("MBD0000000A")
+	mbd.AddStream("\x01CompObj", compObj("Word.Document.8"))
+	mbd.AddStream("WordDocument", []byte("word data"))
+	mbd.AddStorage("ObjectPool").AddStream("Data", []byte("pool"))
+	cfb.Root.AddStorage("MBD0000000B").AddStream("CONTENTS", pdf)
+	buf := &bytes.Buffer{}
+	if _, err := cfb.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := OpenReader(bytes.NewReader(buf.Bytes()), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drawing := escher(0xF, 1, escherDgContainer,
+		escher(0xF, 0, escherSpgrContainer,
+			escher(0xF, 0, escherSpContainer, escher(1, 0, escherSp, le32(1024), le32(5))),
+			pictureShape(1025, 0, "Object 1", 1, 0, 2, 0, 3, 0, 6, 0),
+			pictureShape(1026, 0, "Object 2"),
+			pictureShape(1027, 0, "Control"),
+			pictureShape(1028, 0, "Object 4", 5, 0, 1, 0, 7, 0, 9, 0),
+		),
+	)
+	s := sheetFromRecords(t, wb,
+		biffRecord(0x0EC, drawing),
+		embedObj(1, 0x01, "Package", 1),
+		embedObj(2, 0x01, "Word.Document.8", 10),
+		embedObj(3, 0x10, "Forms.CommandButton.1", 0),
+		embedObj(4, 0x01, "AcroExch.Document.DC", 11),
+	)
+	if len(s.EmbeddedObjects) != 3 {
+		t.Fatalf("got %d objects, want 3", len(s.EmbeddedObjects))
+	}
+	e := s.EmbeddedObjects[0]
+	if e.ProgID != "Package" || e.FileName != "report.pdf" || !bytes.Equal(e.Data, pdf) || e.Name != "Object 1" {
+		t.Errorf("object 0 = %+v", e)
+	}
+	if want := (Anchor{FirstCol: 1, FirstRow: 2, LastCol: 3, LastRow: 6}); e.Anchor != want {
+		t.Errorf("anchor = %+v, want %+v", e.Anchor, want)
+	}
+	e = s.EmbeddedObjects[1]
+	if e.ProgID != "Word.Document.8" || e.FileName != "" {
+		t.Errorf("object 1 = %+v", e)
+	}
+	o, err := ole2.Open(bytes.NewReader(e.Data), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"WordDocument", "ObjectPool/Data", "\x01CompObj"} {
+		if _, err := o.Lookup(path); err != nil {
+			t.Errorf("repackaged object: %v", err)
+		}
+	}
+	e = s.EmbeddedObjects[2]
+	if e.ProgID != "AcroExch.Document.DC" || !bytes.Equal(e.Data, pdf) || e.Anchor.LastRow != 9 {
+		t.Errorf("object 2 = %+v", e)
+	}
+}
@@ -0,0 +1,269 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+)
+
+// ExternalBook is a workbook referenced by formulas, from a SUPBOOK
+// record.
+type ExternalBook struct {
+	// Path is the file path or URL of the workbook. It is empty for the
+	// workbook itself and for add-in functions.
+	Path string
+	// Internal is set for references to the sheets of the workbook
+	// itself.
+	Internal bool
+	AddIn    bool
+	Sheets   []string
+	Names    []ExternalName
+	// Cells holds the values of the referenced cells cached when the
+	// workbook was saved.
+	Cells []ExternalCell
+}
+
+// ExternalName is a defined name or add-in function of an external
+// workbook.
+type ExternalName struct {
+	Name string
+	// Sheet is the zero-based index in Sheets of the sheet the name is
+	// local to, -1 for names of the workbook.
+	Sheet int
+}
+
+// ExternalCell is a cached value of a cell of an external workbook.
+type ExternalCell struct {
+	Sheet int // Zero-based index in Sheets.
+	Row   int
+	Col   int
+	// Value is nil, a float64, string or bool, or a string such as "#N/A"
+	// for error values.
+	Value interface{}
+}
+
+// xti is an EXTERNSHEET entry: a workbook and a range of its sheets.
+type xti struct {
+	book       uint16
+	firstSheet uint16
+	lastSheet  uint16
+}
+
+// parseSupBook reads a SUPBOOK record.
+func (w *WorkBook) parseSupBook(bts []byte) {
+	if len(bts) < 4 {
+		return
+	}
+	ctab := binary.LittleEndian.Uint16(bts)
+	cch := binary.LittleEndian.Uint16(bts[2:])
+	switch cch {
+	case 0x0401:
+		w.ExternalBooks = append(w.ExternalBooks, ExternalBook{Internal: true})
+		return
+	case 0x3A01:
+		w.ExternalBooks = append(w.ExternalBooks, ExternalBook{AddIn: true})
+		return
+	}
+	buf := bytes.NewReader(bts[4:])
+	path, _ := w.getString(buf, cch)
+	b := ExternalBook{Path: decodeVirtPath(path)}
+	for i := uint16(0); i < ctab; i++ {
+		var n uint16
+		if binary.Read(buf, binary.LittleEndian, &n) != nil {
+			break
+		}
+		s, err := w.getString(buf, n)
+		if err != nil {
+			break
+		}
+		b.Sheets = append(b.Sheets, s)
+	}
+	w.ExternalBooks = append(w.ExternalBooks, b)
+}
+
+// decodeVirtPath decodes the encoded file paths of SUPBOOK records, which
+// start with 0x01 and use control characters for volumes and directories.
+func decodeVirtPath(s string) string {
+	if s == "" || s[0] != 0x01 {
+		return s
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0x01: // Volume, or "@" for a UNC path.
+			if i+1 < len(s) {
+				i++
+				if s[i] == '@' {
+					b.WriteString(`\\`)
+				} else {
+					b.WriteString(s[i:i+1] + `:\`)
+				}
+			}
+		case 0x02, 0x03: // Root of the volume, or a directory separator.
+			b.WriteByte('\\')
+		case 0x04: // Parent directory.
+			b.WriteString(`..\`)
+		case 0x05: // URL following its length.
+			i++
+		case 0x06, 0x07, 0x08: // Startup, alternate startup and library directories.
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseExternSheet reads an EXTERNSHEET record.
+func (w *WorkBook) parseExternSheet(bts []byte) {
+	if w.Is5ver || len(bts) < 2 {
+		return
+	}
+	n := int(binary.LittleEndian.Uint16(bts))
+	bts = bts[2:]
+	for i := 0; i < n && len(bts) >= 6; i++ {
+		w.externSheets = append(w.externSheets, xti{
+			book:       binary.LittleEndian.Uint16(bts),
+			firstSheet: binary.LittleEndian.Uint16(bts[2:]),
+			lastSheet:  binary.LittleEndian.Uint16(bts[4:]),
+		})
+		bts = bts[6:]
+	}
+}
+
+// parseExternName reads an EXTERNNAME record of the last SUPBOOK.
+func (w *WorkBook) parseExternName(bts []byte) {
+	if len(w.ExternalBooks) == 0 || len(bts) < 7 {
+		return
+	}
+	b := &w.ExternalBooks[len(w.ExternalBooks)-1]
+	n := ExternalName{Sheet: -1}
+	if !b.AddIn {
+		n.Sheet = int(binary.LittleEndian.Uint16(bts[2:])) - 1
+	}
+	n.Name, _ = w.getString(bytes.NewReader(bts[7:]), uint16(bts[6]))
+	b.Names = append(b.Names, n)
+}
+
+// parseCRN reads the cached values of a CRN record of the sheet set by the
+// last XCT record.
+func (w *WorkBook) parseCRN(bts []byte) {
+	if len(w.ExternalBooks) == 0 || len(bts) < 4 {
+		return
+	}
+	b := &w.ExternalBooks[len(w.ExternalBooks)-1]
+	last, first := int(bts[0]), int(bts[1])
+	row := int(binary.LittleEndian.Uint16(bts[2:]))
+	buf := bytes.NewReader(bts[4:])
+	for col := first; col <= last; col++ {
+		c := ExternalCell{Sheet: w.xctSheet, Row: row, Col: col}
+		var v [9]byte
+		if _, err := io.ReadFull(buf, v[:1]); err != nil {
+			return
+		}
+		if v[0] == 0x02 { // String.
+			var n uint16
+			if binary.Read(buf, binary.LittleEndian, &n) != nil {
+				return
+			}
+			c.Value, _ = w.getString(buf, n)
+			b.Cells = append(b.Cells, c)
+			continue
+		}
+		if _, err := io.ReadFull(buf, v[1:]); err != nil {
+			return
+		}
+		switch v[0] {
+		case 0x01:
+			c.Value = math.Float64frombits(binary.LittleEndian.Uint64(v[1:]))
+		case 0x04:
+			c.Value = v[1] != 0
+		case 0x10:
+			c.Value = errorText[v[1]]
+		}
+		b.Cells = append(b.Cells, c)
+	}
+}
+
+// sheetRef returns the sheet prefix of an EXTERNSHEET index, quoted when
+// needed, with the workbook in brackets for external references.
+func (w *WorkBook) sheetRef(ixti uint16) string {
+	if int(ixti) >= len(w.externSheets) {
+		return "#REF!"
+	}
+	x := w.externSheets[ixti]
+	if int(x.book) >= len(w.ExternalBooks) {
+		return "#REF!"
+	}
+	b := w.ExternalBooks[x.book]
+	sheet := func(i uint16) (string, bool) {
+		switch {
+		case b.Internal && int(i) < len(w.sheets):
+			return w.sheets[i].Name, true
+		case !b.Internal && int(i) < len(b.Sheets):
+			return b.Sheets[i], true
+		}
+		return "", false
+	}
+	first, ok := sheet(x.firstSheet)
+	if !ok {
+		return "#REF!"
+	}
+	name := first
+	if x.lastSheet != x.firstSheet {
+		last, ok := sheet(x.lastSheet)
+		if !ok {
+			return "#REF!"
+		}
+		name += ":" + last
+	}
+	if !b.Internal {
+		name = bookRef(b.Path) + name
+	}
+	return quoteSheet(name) + "!"
+}
+
+// externName returns the name of an EXTERNNAME index of the workbook of an
+// EXTERNSHEET index.
+func (w *WorkBook) externName(ixti uint16, idx uint32) string {
+	if int(ixti) >= len(w.externSheets) {
+		return "#NAME?"
+	}
+	x := w.externSheets[ixti]
+	if int(x.book) >= len(w.ExternalBooks) {
+		return "#NAME?"
+	}
+	b := w.ExternalBooks[x.book]
+	switch {
+	case b.Internal:
+		return w.definedName(idx)
+	case idx == 0 || int(idx) > len(b.Names):
+		return "#NAME?"
+	case b.AddIn:
+		return b.Names[idx-1].Name
+	}
+	return quoteSheet(b.Path) + "!" + b.Names[idx-1].Name
+}
+
+// bookRef returns the path of a workbook with its file name in brackets,
+// as formulas show external workbooks.
+func bookRef(path string) string {
+	i := strings.LastIndexAny(path, `\/`) + 1
+	return path[:i] + "[" + path[i:] + "]"
+}
+
+// quoteSheet quotes a sheet reference unless it starts with a letter and
+// holds only letters, digits, dots and underscores.
+func quoteSheet(s string) string {
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c > 0x7F:
+			continue
+		case i > 0 && (c >= '0' && c <= '9' || c == '.' || c == ':'):
+			continue
+		}
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+	return s
+}
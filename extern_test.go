@@ -0,0 +1,74 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestExternalReferences(t *testing.T) {
+	w := NewWriter()
+	w.AddSheet("Sheet1")
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "\x01\x01@server\x03share\x03Budget.xls"
+	num := make([]byte, 8)
+	binary.LittleEndian.PutUint64(num, math.Float64bits(12.5))
+	stream, _ = insertRecords(stream,
+		biffRecord(0x1AE, le16(1, 0x0401)),
+		biffRecord(0x1AE, cat(le16(2, uint16(len(path))), []byte{0}, []byte(path), mustXLString("Q1", 2), mustXLString("Q2 Plan", 2))),
+		biffRecord(0x023, cat(le16(0, 0, 0), mustXLString("Rate", 1), le16(0))),
+		biffRecord(0x059, le16(0xFFFF, 1)),
+		biffRecord(0x05A, cat([]byte{2, 0}, le16(4), []byte{0x01}, num, []byte{0x02}, mustXLString("abc", 2), []byte{0x10, 0x07}, make([]byte, 7))),
+		biffRecord(0x1AE, le16(1, 0x3A01)),
+		biffRecord(0x023, cat(le16(0), le32(0), mustXLString("EUROCONVERT", 1), le16(0))),
+		biffRecord(0x017, cat(le16(4), le16(0, 0, 0), le16(1, 1, 1), le16(1, 0, 1), le16(2, 0xFFFE, 0xFFFE))),
+	)
+	wb, err := OpenReader(bytes.NewReader(packWorkbook(t, stream)), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wb.ExternalBooks) != 3 {
+		t.Fatalf("got %d books", len(wb.ExternalBooks))
+	}
+	b := wb.ExternalBooks[1]
+	if b.Path != `\\server\share\Budget.xls` || !reflect.DeepEqual(b.Sheets, []string{"Q1", "Q2 Plan"}) {
+		t.Errorf("book = %+v", b)
+	}
+	if !reflect.DeepEqual(b.Names, []ExternalName{{"Rate", -1}}) {
+		t.Errorf("names = %+v", b.Names)
+	}
+	wantCells := []ExternalCell{
+		{1, 4, 0, 12.5},
+		{1, 4, 1, "abc"},
+		{1, 4, 2, "#DIV/0!"},
+	}
+	if !reflect.DeepEqual(b.Cells, wantCells) {
+		t.Errorf("cells = %+v", b.Cells)
+	}
+	if !wb.ExternalBooks[0].Internal || !wb.ExternalBooks[2].AddIn {
+		t.Errorf("books = %+v", wb.ExternalBooks)
+	}
+
+	tests := []struct {
+		rgce []byte
+		want string
+	}{
+		{cat([]byte{0x3A}, le16(0, 0, 0)), "Sheet1!$A$1"},
+		{cat([]byte{0x3A}, le16(1, 4, 1)), `'\\server\share\[Budget.xls]Q2 Plan'!$B$5`},
+		{cat([]byte{0x3B}, le16(2, 0, 9, 0, 0)), `'\\server\share\[Budget.xls]Q1:Q2 Plan'!$A$1:$A$10`},
+		{cat([]byte{0x39}, le16(1), le32(1)), `'\\server\share\Budget.xls'!Rate`},
+		{cat([]byte{0x39}, le16(3), le32(1), ptgNum(1), []byte{0x42, 2}, le16(funcUserDefined)), "EUROCONVERT(1)"},
+		{cat([]byte{0x3A}, le16(9, 0, 0)), "#REF!$A$1"},
+	}
+	for _, tt := range tests {
+		got, err := formulaText(tt.rgce, nil, cellRef{}, wb)
+		if err != nil || got != tt.want {
+			t.Errorf("got %q, %v, want %q", got, err, tt.want)
+		}
+	}
+}
@@ -1,19 +1,162 @@
 package xls
 
+import (
+	"encoding/binary"
+	"image/color"
+	"unicode/utf16"
+)
+
+// FontInfo holds the fields of a FONT record.
 type FontInfo struct {
-	Height     uint16
+	Height     uint16 // In twips.
 	Flag       uint16
 	Color      uint16
-	Bold       uint16
+	Bold       uint16 // Weight, 400 is normal and 700 bold.
 	Escapement uint16
 	Underline  byte
 	Family     byte
 	Charset    byte
 	Notused    byte
-	NameB      byte
+	NameLen    byte // Characters in the font name.
+	HighByte   bool // The name is stored in UTF-16, BIFF8 only.
 }
 
+// Underline style of a font.
+type Underline byte
+
+const (
+	UnderlineNone             Underline = 0x00
+	UnderlineSingle           Underline = 0x01
+	UnderlineDouble           Underline = 0x02
+	UnderlineSingleAccounting Underline = 0x21
+	UnderlineDoubleAccounting Underline = 0x22
+)
+
+// Script is the vertical position of a font.
+type Script byte
+
+const (
+	ScriptNone Script = iota
+	ScriptSuper
+	ScriptSub
+)
+
+// Font family values.
+const (
+	FamilyNone       = 0
+	FamilyRoman      = 1
+	FamilySwiss      = 2
+	FamilyModern     = 3
+	FamilyScript     = 4
+	FamilyDecorative = 5
+)
+
 type Font struct {
 	Info *FontInfo
 	Name string
+
+	Size      float64 // In points.
+	Weight    uint16  // 400 is normal and 700 bold.
+	Bold      bool
+	Italic    bool
+	Strikeout bool
+	Outline   bool
+	Shadow    bool
+	Underline Underline
+	Script    Script
+	Family    byte
+	Charset   byte
+	// ColorIndex is the palette index of the font color and Color its
+	// value resolved with the workbook palette.
+	ColorIndex uint16
+	Color      color.RGBA
+}
+
+// parseFont decodes a FONT record. A truncated name is kept as far as it
+// was read.
+func (w *WorkBook) parseFont(bts []byte) Font {
+	var head [15]byte
+	copy(head[:], bts)
+	info := &FontInfo{
+		Height:     binary.LittleEndian.Uint16(head[0:]),
+		Flag:       binary.LittleEndian.Uint16(head[2:]),
+		Color:      binary.LittleEndian.Uint16(head[4:]),
+		Bold:       binary.LittleEndian.Uint16(head[6:]),
+		Escapement: binary.LittleEndian.Uint16(head[8:]),
+		Underline:  head[10],
+		Family:     head[11],
+		Charset:    head[12],
+		Notused:    head[13],
+		NameLen:    head[14],
+	}
+	var name string
+	if len(bts) > 15 {
+		rgb := bts[15:]
+		if w.Is5ver {
+			// The BIFF5 name has no flag byte.
+			if int(info.NameLen) < len(rgb) {
+				rgb = rgb[:info.NameLen]
+			}
+			name = decodeWindows1251(rgb)
+		} else {
+			info.HighByte = rgb[0]&0x1 != 0
+			rgb = rgb[1:]
+			width := 1
+			if info.HighByte {
+				width = 2
+			}
+			n := int(info.NameLen)
+			if n > len(rgb)/width {
+				n = len(rgb) / width
+			}
+			u := make([]uint16, n)
+			for i := range u {
+				if width == 2 {
+					u[i] = binary.LittleEndian.Uint16(rgb[2*i:])
+				} else {
+					u[i] = uint16(rgb[i])
+				}
+			}
+			name = string(utf16.Decode(u))
+		}
+	}
+	return Font{
+		Info:       info,
+		Name:       name,
+		Size:       float64(info.Height) / 20,
+		Weight:     info.Bold,
+		Bold:       info.Bold >= 600,
+		Italic:     info.Flag&0x02 != 0,
+		Strikeout:  info.Flag&0x08 != 0,
+		Outline:    info.Flag&0x10 != 0,
+		Shadow:     info.Flag&0x20 != 0,
+		Underline:  Underline(info.Underline),
+		Script:     Script(info.Escapement),
+		Family:     info.Family,
+		Charset:    info.Charset,
+		ColorIndex: info.Color,
+	}
+}
+
+// resolveFonts sets the font colors once the palette is known.
+func (w *WorkBook) resolveFonts() {
+	for i := range w.Fonts {
+		w.Fonts[i].Color = w.Color(w.Fonts[i].ColorIndex)
+	}
+}
+
+// Font returns the font at a font index as used by XF records. Index 4
+// does not exist, so later fonts are stored one position earlier in Fonts.
+func (w *WorkBook) Font(idx uint16) (Font, bool) {
+	i := int(idx)
+	switch {
+	case i == 4:
+		return Font{}, false
+	case i > 4:
+		i--
+	}
+	if i >= len(w.Fonts) {
+		return Font{}, false
+	}
+	return w.Fonts[i], true
 }
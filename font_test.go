@@ -0,0 +1,80 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+)
+
+func TestFont(t *testing.T) {
+	w := NewWriter()
+	header := w.AddFont(WriteFont{Name: "Verdana", Height: 280, Bold: true, Underline: true, Color: 10})
+	note := w.AddFont(WriteFont{Name: "Courier New", Italic: true, Strikeout: true})
+	s := w.AddSheet("Sheet1")
+	s.SetString(0, 0, "Header", w.AddStyle(WriteStyle{Font: header}))
+	s.SetString(1, 0, "note", w.AddStyle(WriteStyle{Font: note}))
+	s.SetString(2, 0, "plain", 0)
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Redefine palette index 10 to check colors resolve after parsing.
+	body := make([]byte, 2, 2+56*4)
+	binary.LittleEndian.PutUint16(body, 56)
+	for i := 0; i < 56; i++ {
+		body = append(body, byte(i), 0x80, 0x40, 0)
+	}
+	stream, _ = insertRecords(stream, biffRecord(0x92, body))
+
+	wb, err := OpenReader(bytes.NewReader(packWorkbook(t, stream)), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := sheet.Row(0).Font(0)
+	if !ok {
+		t.Fatal("missing header font")
+	}
+	if f.Name != "Verdana" || f.Size != 14 || !f.Bold || f.Weight != 700 || f.Underline != UnderlineSingle || f.Italic {
+		t.Fatalf("got header font %+v", f)
+	}
+	if f.ColorIndex != 10 || f.Color != (color.RGBA{2, 0x80, 0x40, 0xFF}) {
+		t.Fatalf("got header color %d %v", f.ColorIndex, f.Color)
+	}
+	f, ok = sheet.Row(1).Font(0)
+	if !ok || f.Name != "Courier New" || f.Bold || !f.Italic || !f.Strikeout || f.Size != 10 || f.Script != ScriptNone {
+		t.Fatalf("got note font %+v", f)
+	}
+	f, ok = sheet.Row(2).Font(0)
+	if !ok || f.Name != "Arial" || f.Bold {
+		t.Fatalf("got default font %+v", f)
+	}
+	if _, ok := wb.Font(4); ok {
+		t.Fatal("font index 4 must not exist")
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	head := cat(le16(240, 0x2, 10, 700, 1), []byte{1, FamilySwiss, 204, 0})
+	wb := &WorkBook{}
+	f := wb.parseFont(cat(head, []byte{5}, mustXLString("Шрифт", 1)[1:]))
+	if f.Name != "Шрифт" || f.Info.NameLen != 5 || !f.Info.HighByte || f.Script != ScriptSuper || f.Charset != 204 {
+		t.Errorf("got %+v, info %+v", f, f.Info)
+	}
+	f = wb.parseFont(cat(head, mustXLString("Arial", 1)))
+	if f.Name != "Arial" || f.Info.NameLen != 5 || f.Info.HighByte || f.Family != FamilySwiss {
+		t.Errorf("got %+v, info %+v", f, f.Info)
+	}
+	// A truncated name keeps the characters read.
+	if f = wb.parseFont(cat(head, []byte{5, 0}, []byte("Ar"))); f.Name != "Ar" {
+		t.Errorf("got name %q", f.Name)
+	}
+	wb.Is5ver = true
+	if f = wb.parseFont(cat(head, []byte{5}, []byte("Arial"))); f.Name != "Arial" || f.Info.HighByte {
+		t.Errorf("got BIFF5 font %+v", f)
+	}
+}
@@ -0,0 +1,443 @@
+package xls
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var errFormulaTruncated = errors.New("formula: truncated token")
+
+// formulaContext resolves the names and sheets referenced by a formula.
+type formulaContext interface {
+	// sheetRef returns the sheet prefix, such as "Sheet1!", of an
+	// EXTERNSHEET index.
+	sheetRef(ixti uint16) string
+	// definedName returns the name at a one-based NAME record index.
+	definedName(idx uint32) string
+	// externName returns the name at a one-based EXTERNNAME index of the
+	// workbook referenced by an EXTERNSHEET index.
+	externName(ixti uint16, idx uint32) string
+}
+
+// cellRef is a zero-based cell position.
+type cellRef struct {
+	row, col int
+}
+
+// colName returns the letters of a zero-based column.
+func colName(col int) string {
+	var b []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		b = append([]byte{byte('A' + (col-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// cellName returns the A1 name of a zero-based cell.
+func cellName(row, col int) string {
+	return colName(col) + strconv.Itoa(row+1)
+}
+
+// formulaText decodes the parsed tokens of a BIFF8 formula to its text
+// without the leading "=". Relative references of ptgRefN and ptgAreaN
+// tokens are resolved against base. extra holds the array constants and
+// other additional data following the tokens.
+func formulaText(rgce, extra []byte, base cellRef, ctx formulaContext) (string, error) {
+	p := &formulaParser{b: rgce, extra: extra, base: base, ctx: ctx}
+	p.parse()
+	if p.err != nil {
+		return "", p.err
+	}
+	if len(p.stack) != 1 {
+		return "", fmt.Errorf("formula: %d values left on the stack", len(p.stack))
+	}
+	return p.stack[0], nil
+}
+
+// formulaString returns the value of a formula made of one string token.
+func formulaString(rgce []byte) (string, bool) {
+	if len(rgce) < 3 || rgce[0] != 0x17 {
+		return "", false
+	}
+	p := &formulaParser{b: rgce, pos: 1}
+	s := p.str(int(p.u8()))
+	if p.err != nil || p.pos != len(rgce) {
+		return "", false
+	}
+	return s, true
+}
+
+var binaryOps = map[byte]string{
+	0x03: "+",
+	0x04: "-",
+	0x05: "*",
+	0x06: "/",
+	0x07: "^",
+	0x08: "&",
+	0x09: "<",
+	0x0A: "<=",
+	0x0B: "=",
+	0x0C: ">=",
+	0x0D: ">",
+	0x0E: "<>",
+	0x0F: " ",
+	0x10: ",",
+	0x11: ":",
+}
+
+type formulaParser struct {
+	b     []byte
+	pos   int
+	extra []byte
+	base  cellRef
+	ctx   formulaContext
+	stack []string
+	err   error
+}
+
+func (p *formulaParser) next(n int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if p.pos+n > len(p.b) {
+		p.err = errFormulaTruncated
+		return nil
+	}
+	b := p.b[p.pos : p.pos+n]
+	p.pos += n
+	return b
+}
+
+func (p *formulaParser) u8() byte {
+	if b := p.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (p *formulaParser) u16() uint16 {
+	if b := p.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (p *formulaParser) u32() uint32 {
+	if b := p.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (p *formulaParser) f64() float64 {
+	if b := p.next(8); b != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+// str reads cch characters preceded by a flag byte.
+func (p *formulaParser) str(cch int) string {
+	wide := p.u8()&0x1 != 0
+	u := make([]uint16, cch)
+	for i := range u {
+		if wide {
+			u[i] = p.u16()
+		} else {
+			u[i] = uint16(p.u8())
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+func (p *formulaParser) push(s string) {
+	p.stack = append(p.stack, s)
+}
+
+func (p *formulaParser) pop() string {
+	if len(p.stack) == 0 {
+		if p.err == nil {
+			p.err = errors.New("formula: missing operand")
+		}
+		return ""
+	}
+	s := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	return s
+}
+
+// popArgs removes the last n values in their original order.
+func (p *formulaParser) popArgs(n int) []string {
+	args := make([]string, n)
+	for i := n - 1; i >= 0; i-- {
+		args[i] = p.pop()
+	}
+	return args
+}
+
+// extraParser reads the additional data following the tokens.
+func (p *formulaParser) extraParser() *formulaParser {
+	return &formulaParser{b: p.extra}
+}
+
+func (p *formulaParser) parse() {
+	for p.pos < len(p.b) && p.err == nil {
+		ptg := p.u8()
+		if op, ok := binaryOps[ptg]; ok {
+			b, a := p.pop(), p.pop()
+			p.push(a + op + b)
+			continue
+		}
+		// Operand and function tokens come in reference, value and array
+		// classes that are rendered alike.
+		if ptg >= 0x20 {
+			ptg = ptg&0x1F | 0x20
+		}
+		switch ptg {
+		case 0x12: // ptgUplus
+			p.push("+" + p.pop())
+		case 0x13: // ptgUminus
+			p.push("-" + p.pop())
+		case 0x14: // ptgPercent
+			p.push(p.pop() + "%")
+		case 0x15: // ptgParen
+			p.push("(" + p.pop() + ")")
+		case 0x16: // ptgMissArg
+			p.push("")
+		case 0x17: // ptgStr
+			s := p.str(int(p.u8()))
+			p.push(`"` + strings.Replace(s, `"`, `""`, -1) + `"`)
+		case 0x19: // ptgAttr
+			p.attr()
+		case 0x1C: // ptgErr
+			p.push(errorText[p.u8()])
+		case 0x1D: // ptgBool
+			if p.u8() != 0 {
+				p.push("TRUE")
+			} else {
+				p.push("FALSE")
+			}
+		case 0x1E: // ptgInt
+			p.push(strconv.Itoa(int(p.u16())))
+		case 0x1F: // ptgNum
+			p.push(numberText(p.f64()))
+		case 0x20: // ptgArray
+			p.next(7)
+			p.push(p.array())
+		case 0x21: // ptgFunc
+			id := p.u16()
+			f, ok := funcs[id]
+			if id&0x8000 != 0 { // Macro command.
+				f, ok = command(id&0x7FFF), true
+				if f.args < 0 {
+					p.fail("formula: unknown argument count of command %s", f.name)
+					return
+				}
+			}
+			if !ok || f.args < 0 {
+				p.fail("formula: unknown function %d", id)
+				return
+			}
+			p.push(f.name + "(" + strings.Join(p.popArgs(f.args), ",") + ")")
+		case 0x22: // ptgFuncVar
+			argc := int(p.u8() & 0x7F)
+			tab := p.u16()
+			id := tab & 0x7FFF
+			args := p.popArgs(argc)
+			if tab&0x8000 != 0 { // Macro command.
+				p.push(command(id).name + "(" + strings.Join(args, ",") + ")")
+				continue
+			}
+			if id == funcUserDefined && argc > 0 {
+				p.push(args[0] + "(" + strings.Join(args[1:], ",") + ")")
+				continue
+			}
+			f, ok := funcs[id]
+			if !ok {
+				p.fail("formula: unknown function %d", id)
+				return
+			}
+			p.push(f.name + "(" + strings.Join(args, ",") + ")")
+		case 0x23: // ptgName
+			p.push(p.ctx.definedName(p.u32()))
+		case 0x24: // ptgRef
+			row, col := p.u16(), p.u16()
+			p.push(p.cellText(row, col, false))
+		case 0x25: // ptgArea
+			p.push(p.areaText(false))
+		case 0x26: // ptgMemArea
+			p.next(6)
+			p.skipMemArea()
+		case 0x27, 0x28: // ptgMemErr, ptgMemNoMem
+			p.next(6)
+		case 0x29: // ptgMemFunc
+			p.next(2)
+		case 0x2A: // ptgRefErr
+			p.next(4)
+			p.push("#REF!")
+		case 0x2B: // ptgAreaErr
+			p.next(8)
+			p.push("#REF!")
+		case 0x2C: // ptgRefN
+			row, col := p.u16(), p.u16()
+			p.push(p.cellText(row, col, true))
+		case 0x2D: // ptgAreaN
+			p.push(p.areaText(true))
+		case 0x39: // ptgNameX
+			ixti := p.u16()
+			p.push(p.ctx.externName(ixti, p.u32()))
+		case 0x3A: // ptgRef3d
+			sheet := p.ctx.sheetRef(p.u16())
+			row, col := p.u16(), p.u16()
+			p.push(sheet + p.cellText(row, col, false))
+		case 0x3B: // ptgArea3d
+			sheet := p.ctx.sheetRef(p.u16())
+			p.push(sheet + p.areaText(false))
+		case 0x3C: // ptgRefErr3d
+			sheet := p.ctx.sheetRef(p.u16())
+			p.next(4)
+			p.push(sheet + "#REF!")
+		case 0x3D: // ptgAreaErr3d
+			sheet := p.ctx.sheetRef(p.u16())
+			p.next(8)
+			p.push(sheet + "#REF!")
+		default:
+			p.fail("formula: unsupported token 0x%02X", ptg)
+			return
+		}
+	}
+}
+
+func (p *formulaParser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// attr handles the control tokens of ptgAttr. Only SUM of a single
+// argument changes the formula text.
+func (p *formulaParser) attr() {
+	flags := p.u8()
+	data := p.u16()
+	switch {
+	case flags&0x04 != 0: // tAttrChoose
+		p.next(2 * (int(data) + 1))
+	case flags&0x10 != 0: // tAttrSum
+		p.push("SUM(" + p.pop() + ")")
+	}
+}
+
+// cellPos decodes a cell reference. The column field holds the relative
+// row and column flags in its high bits.
+func (p *formulaParser) cellPos(row, col uint16, offset bool) (r int, rowRel bool, c int, colRel bool) {
+	rowRel, colRel = col&0x8000 != 0, col&0x4000 != 0
+	r, c = int(row), int(col&0x3FFF)
+	if offset && rowRel {
+		r = (p.base.row + int(int16(row))) & 0xFFFF
+	}
+	if offset && colRel {
+		c = (p.base.col + int(int8(col))) & 0xFF
+	}
+	return
+}
+
+func (p *formulaParser) cellText(row, col uint16, offset bool) string {
+	r, rowRel, c, colRel := p.cellPos(row, col, offset)
+	return colText(c, colRel) + rowText(r, rowRel)
+}
+
+func colText(col int, rel bool) string {
+	if rel {
+		return colName(col)
+	}
+	return "$" + colName(col)
+}
+
+func rowText(row int, rel bool) string {
+	if rel {
+		return strconv.Itoa(row + 1)
+	}
+	return "$" + strconv.Itoa(row+1)
+}
+
+// areaText reads and renders an area reference. Areas covering whole rows
+// or columns use the short forms 1:2 and A:B.
+func (p *formulaParser) areaText(offset bool) string {
+	row1, row2, col1, col2 := p.u16(), p.u16(), p.u16(), p.u16()
+	r1, rowRel1, c1, colRel1 := p.cellPos(row1, col1, offset)
+	r2, rowRel2, c2, colRel2 := p.cellPos(row2, col2, offset)
+	switch {
+	case !offset && r1 == 0 && r2 == 0xFFFF:
+		return colText(c1, colRel1) + ":" + colText(c2, colRel2)
+	case !offset && c1 == 0 && c2 == 0xFF:
+		return rowText(r1, rowRel1) + ":" + rowText(r2, rowRel2)
+	}
+	return colText(c1, colRel1) + rowText(r1, rowRel1) + ":" + colText(c2, colRel2) + rowText(r2, rowRel2)
+}
+
+// skipMemArea consumes the areas of a ptgMemArea from the extra data.
+func (p *formulaParser) skipMemArea() {
+	if p.extra == nil {
+		return
+	}
+	x := p.extraParser()
+	x.next(8 * int(x.u16()))
+	p.extra = p.extra[x.pos:]
+}
+
+// array renders the next array constant of the extra data.
+func (p *formulaParser) array() string {
+	x := p.extraParser()
+	cols := int(x.u8()) + 1
+	rows := int(x.u16()) + 1
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for r := 0; r < rows && x.err == nil; r++ {
+		if r > 0 {
+			sb.WriteByte(';')
+		}
+		for c := 0; c < cols && x.err == nil; c++ {
+			if c > 0 {
+				sb.WriteByte(',')
+			}
+			switch x.u8() {
+			case 0x01:
+				sb.WriteString(numberText(x.f64()))
+			case 0x02:
+				s := x.str(int(x.u16()))
+				sb.WriteString(`"` + strings.Replace(s, `"`, `""`, -1) + `"`)
+			case 0x04:
+				if x.u8() != 0 {
+					sb.WriteString("TRUE")
+				} else {
+					sb.WriteString("FALSE")
+				}
+				x.next(7)
+			case 0x10:
+				sb.WriteString(errorText[x.u8()])
+				x.next(7)
+			default:
+				x.next(8)
+			}
+		}
+	}
+	sb.WriteByte('}')
+	if x.err != nil {
+		p.fail("formula: truncated array constant")
+	}
+	p.extra = p.extra[x.pos:]
+	return sb.String()
+}
+
+// numberText formats a number as Excel shows it in formulas.
+func numberText(f float64) string {
+	if a := math.Abs(f); a == 0 || (a >= 1e-5 && a < 1e15) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'E', -1, 64)
+}
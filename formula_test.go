@@ -0,0 +1,73 @@
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func ptgStr(s string) []byte {
+	return append([]byte{0x17, byte(len(s)), 0}, s...)
+}
+
+func ptgNum(f float64) []byte {
+	b := make([]byte, 9)
+	b[0] = 0x1F
+	binary.LittleEndian.PutUint64(b[1:], math.Float64bits(f))
+	return b
+}
+
+func cat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func TestColName(t *testing.T) {
+	for col, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 255: "IV", 701: "ZZ", 702: "AAA"} {
+		if got := colName(col); got != want {
+			t.Errorf("colName(%d) = %q, want %q", col, got, want)
+		}
+	}
+}
+
+func TestFormulaText(t *testing.T) {
+	wb := &WorkBook{}
+	array := cat([]byte{1}, le16(1), []byte{1}, ptgNum(1)[1:], []byte{2}, le16(1), []byte{0, 'a'},
+		[]byte{4, 1, 0, 0, 0, 0, 0, 0, 0}, []byte{0x10, 0x2A, 0, 0, 0, 0, 0, 0, 0})
+	tests := []struct {
+		name  string
+		rgce  []byte
+		extra []byte
+		want  string
+	}{
+		{"sum", cat([]byte{0x25}, le16(0, 1, 0xC000, 0xC001), []byte{0x42, 1}, le16(4), []byte{0x1E}, le16(1), []byte{0x03}), nil, "SUM(A1:B2)+1"},
+		{"if", cat([]byte{0x44}, le16(0, 0), []byte{0x1E}, le16(0), []byte{0x0D}, ptgStr("yes"), ptgStr(`"no"`), []byte{0x42, 3}, le16(1)), nil, `IF($A$1>0,"yes","""no""")`},
+		{"refn", cat([]byte{0x2C}, le16(0xFFFF, 0xC001)), nil, "D5"},
+		{"array", cat([]byte{0x60}, make([]byte, 7)), array, `{1,"a";TRUE,#N/A}`},
+		{"column", cat([]byte{0x25}, le16(0, 0xFFFF, 0x8002, 0x8003)), nil, "$C:$D"},
+		{"rows", cat([]byte{0x25}, le16(1, 2, 0x8000, 0x80FF)), nil, "2:3"},
+		{"func", cat(ptgStr("x"), []byte{0x41}, le16(32)), nil, `LEN("x")`},
+		{"paren", cat(ptgNum(2.5), []byte{0x13, 0x15, 0x14}), nil, "(-2.5)%"},
+		{"attrsum", cat([]byte{0x25}, le16(0, 9, 0xC000, 0xC000), []byte{0x19, 0x10}, le16(0)), nil, "SUM(A1:A10)"},
+		{"sheet", cat([]byte{0x3A}, le16(0, 0, 0)), nil, "#REF!$A$1"},
+	}
+	for _, tt := range tests {
+		got, err := formulaText(tt.rgce, tt.extra, cellRef{row: 5, col: 2}, wb)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, rgce := range [][]byte{{0x18, 0}, {0x24, 0}, {0x03}} {
+		if _, err := formulaText(rgce, nil, cellRef{}, wb); err == nil {
+			t.Errorf("% X: expected error", rgce)
+		}
+	}
+}
@@ -0,0 +1,738 @@
+package xls
+
+import "strconv"
+
+// funcInfo describes a built-in function of the formula function table.
+type funcInfo struct {
+	name string
+	args int // Fixed argument count, -1 if variable.
+}
+
+// funcs maps function table indices of ptgFunc and ptgFuncVar tokens to
+// worksheet and macro sheet functions.
+var funcs = map[uint16]funcInfo{
+	0:   {"COUNT", -1},
+	1:   {"IF", -1},
+	2:   {"ISNA", 1},
+	3:   {"ISERROR", 1},
+	4:   {"SUM", -1},
+	5:   {"AVERAGE", -1},
+	6:   {"MIN", -1},
+	7:   {"MAX", -1},
+	8:   {"ROW", -1},
+	9:   {"COLUMN", -1},
+	10:  {"NA", 0},
+	11:  {"NPV", -1},
+	12:  {"STDEV", -1},
+	13:  {"DOLLAR", -1},
+	14:  {"FIXED", -1},
+	15:  {"SIN", 1},
+	16:  {"COS", 1},
+	17:  {"TAN", 1},
+	18:  {"ATAN", 1},
+	19:  {"PI", 0},
+	20:  {"SQRT", 1},
+	21:  {"EXP", 1},
+	22:  {"LN", 1},
+	23:  {"LOG10", 1},
+	24:  {"ABS", 1},
+	25:  {"INT", 1},
+	26:  {"SIGN", 1},
+	27:  {"ROUND", 2},
+	28:  {"LOOKUP", -1},
+	29:  {"INDEX", -1},
+	30:  {"REPT", 2},
+	31:  {"MID", 3},
+	32:  {"LEN", 1},
+	33:  {"VALUE", 1},
+	34:  {"TRUE", 0},
+	35:  {"FALSE", 0},
+	36:  {"AND", -1},
+	37:  {"OR", -1},
+	38:  {"NOT", 1},
+	39:  {"MOD", 2},
+	40:  {"DCOUNT", 3},
+	41:  {"DSUM", 3},
+	42:  {"DAVERAGE", 3},
+	43:  {"DMIN", 3},
+	44:  {"DMAX", 3},
+	45:  {"DSTDEV", 3},
+	46:  {"VAR", -1},
+	47:  {"DVAR", 3},
+	48:  {"TEXT", 2},
+	49:  {"LINEST", -1},
+	50:  {"TREND", -1},
+	51:  {"LOGEST", -1},
+	52:  {"GROWTH", -1},
+	53:  {"GOTO", 1},
+	54:  {"HALT", -1},
+	55:  {"RETURN", -1},
+	56:  {"PV", -1},
+	57:  {"FV", -1},
+	58:  {"NPER", -1},
+	59:  {"PMT", -1},
+	60:  {"RATE", -1},
+	61:  {"MIRR", 3},
+	62:  {"IRR", -1},
+	63:  {"RAND", 0},
+	64:  {"MATCH", -1},
+	65:  {"DATE", 3},
+	66:  {"TIME", 3},
+	67:  {"DAY", 1},
+	68:  {"MONTH", 1},
+	69:  {"YEAR", 1},
+	70:  {"WEEKDAY", -1},
+	71:  {"HOUR", 1},
+	72:  {"MINUTE", 1},
+	73:  {"SECOND", 1},
+	74:  {"NOW", 0},
+	75:  {"AREAS", 1},
+	76:  {"ROWS", 1},
+	77:  {"COLUMNS", 1},
+	78:  {"OFFSET", -1},
+	79:  {"ABSREF", 2},
+	80:  {"RELREF", 2},
+	81:  {"ARGUMENT", -1},
+	82:  {"SEARCH", -1},
+	83:  {"TRANSPOSE", 1},
+	84:  {"ERROR", -1},
+	85:  {"STEP", 0},
+	86:  {"TYPE", 1},
+	87:  {"ECHO", -1},
+	88:  {"SET.NAME", -1},
+	89:  {"CALLER", 0},
+	90:  {"DEREF", 1},
+	91:  {"WINDOWS", -1},
+	92:  {"SERIES", -1},
+	93:  {"DOCUMENTS", -1},
+	94:  {"ACTIVE.CELL", 0},
+	95:  {"SELECTION", 0},
+	96:  {"RESULT", -1},
+	97:  {"ATAN2", 2},
+	98:  {"ASIN", 1},
+	99:  {"ACOS", 1},
+	100: {"CHOOSE", -1},
+	101: {"HLOOKUP", -1},
+	102: {"VLOOKUP", -1},
+	103: {"LINKS", -1},
+	104: {"INPUT", -1},
+	105: {"ISREF", 1},
+	106: {"GET.FORMULA", 1},
+	107: {"GET.NAME", 1},
+	108: {"SET.VALUE", 2},
+	109: {"LOG", -1},
+	110: {"EXEC", -1},
+	111: {"CHAR", 1},
+	112: {"LOWER", 1},
+	113: {"UPPER", 1},
+	114: {"PROPER", 1},
+	115: {"LEFT", -1},
+	116: {"RIGHT", -1},
+	117: {"EXACT", 2},
+	118: {"TRIM", 1},
+	119: {"REPLACE", 4},
+	120: {"SUBSTITUTE", -1},
+	121: {"CODE", 1},
+	122: {"NAMES", -1},
+	123: {"DIRECTORY", -1},
+	124: {"FIND", -1},
+	125: {"CELL", -1},
+	126: {"ISERR", 1},
+	127: {"ISTEXT", 1},
+	128: {"ISNUMBER", 1},
+	129: {"ISBLANK", 1},
+	130: {"T", 1},
+	131: {"N", 1},
+	132: {"FOPEN", -1},
+	133: {"FCLOSE", 1},
+	134: {"FSIZE", 1},
+	135: {"FREADLN", 1},
+	136: {"FREAD", 2},
+	137: {"FWRITELN", 2},
+	138: {"FWRITE", 2},
+	139: {"FPOS", -1},
+	140: {"DATEVALUE", 1},
+	141: {"TIMEVALUE", 1},
+	142: {"SLN", 3},
+	143: {"SYD", 4},
+	144: {"DDB", -1},
+	145: {"GET.DEF", -1},
+	146: {"REFTEXT", -1},
+	147: {"TEXTREF", -1},
+	148: {"INDIRECT", -1},
+	149: {"REGISTER", -1},
+	150: {"CALL", -1},
+	151: {"ADD.BAR", -1},
+	152: {"ADD.MENU", -1},
+	153: {"ADD.COMMAND", -1},
+	154: {"ENABLE.COMMAND", -1},
+	155: {"CHECK.COMMAND", -1},
+	156: {"RENAME.COMMAND", -1},
+	157: {"SHOW.BAR", -1},
+	158: {"DELETE.MENU", -1},
+	159: {"DELETE.COMMAND", -1},
+	160: {"GET.CHART.ITEM", -1},
+	161: {"DIALOG.BOX", 1},
+	162: {"CLEAN", 1},
+	163: {"MDETERM", 1},
+	164: {"MINVERSE", 1},
+	165: {"MMULT", 2},
+	167: {"IPMT", -1},
+	168: {"PPMT", -1},
+	169: {"COUNTA", -1},
+	175: {"INITIATE", 2},
+	176: {"REQUEST", 2},
+	177: {"POKE", 3},
+	178: {"EXECUTE", 2},
+	179: {"TERMINATE", 1},
+	180: {"RESTART", -1},
+	181: {"HELP", -1},
+	182: {"GET.BAR", -1},
+	183: {"PRODUCT", -1},
+	184: {"FACT", 1},
+	185: {"GET.CELL", -1},
+	186: {"GET.WORKSPACE", 1},
+	187: {"GET.WINDOW", -1},
+	188: {"GET.DOCUMENT", -1},
+	189: {"DPRODUCT", 3},
+	190: {"ISNONTEXT", 1},
+	193: {"STDEVP", -1},
+	194: {"VARP", -1},
+	195: {"DSTDEVP", 3},
+	196: {"DVARP", 3},
+	197: {"TRUNC", -1},
+	198: {"ISLOGICAL", 1},
+	199: {"DCOUNTA", 3},
+	204: {"USDOLLAR", -1},
+	205: {"FINDB", -1},
+	206: {"SEARCHB", -1},
+	207: {"REPLACEB", 4},
+	208: {"LEFTB", -1},
+	209: {"RIGHTB", -1},
+	210: {"MIDB", 3},
+	211: {"LENB", 1},
+	212: {"ROUNDUP", 2},
+	213: {"ROUNDDOWN", 2},
+	214: {"ASC", 1},
+	215: {"DBCS", 1},
+	216: {"RANK", -1},
+	219: {"ADDRESS", -1},
+	220: {"DAYS360", -1},
+	221: {"TODAY", 0},
+	222: {"VDB", -1},
+	227: {"MEDIAN", -1},
+	228: {"SUMPRODUCT", -1},
+	229: {"SINH", 1},
+	230: {"COSH", 1},
+	231: {"TANH", 1},
+	232: {"ASINH", 1},
+	233: {"ACOSH", 1},
+	234: {"ATANH", 1},
+	235: {"DGET", 3},
+	244: {"INFO", 1},
+	247: {"DB", -1},
+	252: {"FREQUENCY", 2},
+	261: {"ERROR.TYPE", 1},
+	269: {"AVEDEV", -1},
+	270: {"BETADIST", -1},
+	271: {"GAMMALN", 1},
+	272: {"BETAINV", -1},
+	273: {"BINOMDIST", 4},
+	274: {"CHIDIST", 2},
+	275: {"CHIINV", 2},
+	276: {"COMBIN", 2},
+	277: {"CONFIDENCE", 3},
+	278: {"CRITBINOM", 3},
+	279: {"EVEN", 1},
+	280: {"EXPONDIST", 3},
+	281: {"FDIST", 3},
+	282: {"FINV", 3},
+	283: {"FISHER", 1},
+	284: {"FISHERINV", 1},
+	285: {"FLOOR", 2},
+	286: {"GAMMADIST", 4},
+	287: {"GAMMAINV", 3},
+	288: {"CEILING", 2},
+	289: {"HYPGEOMDIST", 4},
+	290: {"LOGNORMDIST", 3},
+	291: {"LOGINV", 3},
+	292: {"NEGBINOMDIST", 3},
+	293: {"NORMDIST", 4},
+	294: {"NORMSDIST", 1},
+	295: {"NORMINV", 3},
+	296: {"NORMSINV", 1},
+	297: {"STANDARDIZE", 3},
+	298: {"ODD", 1},
+	299: {"PERMUT", 2},
+	300: {"POISSON", 3},
+	301: {"TDIST", 3},
+	302: {"WEIBULL", 4},
+	303: {"SUMXMY2", 2},
+	304: {"SUMX2MY2", 2},
+	305: {"SUMX2PY2", 2},
+	306: {"CHITEST", 2},
+	307: {"CORREL", 2},
+	308: {"COVAR", 2},
+	309: {"FORECAST", 3},
+	310: {"FTEST", 2},
+	311: {"INTERCEPT", 2},
+	312: {"PEARSON", 2},
+	313: {"RSQ", 2},
+	314: {"STEYX", 2},
+	315: {"SLOPE", 2},
+	316: {"TTEST", 4},
+	317: {"PROB", -1},
+	318: {"DEVSQ", -1},
+	319: {"GEOMEAN", -1},
+	320: {"HARMEAN", -1},
+	321: {"SUMSQ", -1},
+	322: {"KURT", -1},
+	323: {"SKEW", -1},
+	324: {"ZTEST", -1},
+	325: {"LARGE", 2},
+	326: {"SMALL", 2},
+	327: {"QUARTILE", 2},
+	328: {"PERCENTILE", 2},
+	329: {"PERCENTRANK", -1},
+	330: {"MODE", -1},
+	331: {"TRIMMEAN", 2},
+	332: {"TINV", 2},
+	336: {"CONCATENATE", -1},
+	337: {"POWER", 2},
+	342: {"RADIANS", 1},
+	343: {"DEGREES", 1},
+	344: {"SUBTOTAL", -1},
+	345: {"SUMIF", -1},
+	346: {"COUNTIF", 2},
+	347: {"COUNTBLANK", 1},
+	350: {"ISPMT", 4},
+	351: {"DATEDIF", 3},
+	352: {"DATESTRING", 1},
+	353: {"NUMBERSTRING", 2},
+	354: {"ROMAN", -1},
+	358: {"GETPIVOTDATA", -1},
+	359: {"HYPERLINK", -1},
+	360: {"PHONETIC", 1},
+	361: {"AVERAGEA", -1},
+	362: {"MAXA", -1},
+	363: {"MINA", -1},
+	364: {"STDEVPA", -1},
+	365: {"VARPA", -1},
+	366: {"STDEVA", -1},
+	367: {"VARA", -1},
+}
+
+// funcUserDefined is the function index of add-in and macro functions, whose
+// name is passed as the first argument.
+const funcUserDefined = 255
+
+// commands maps the command table (Cetab) indices of function tokens with
+// the command bit set to the macro commands of Excel 4.0 macro sheets. Most
+// commands take optional arguments and only appear in ptgFuncVar tokens.
+var commands = map[uint16]funcInfo{
+	0:   {"BEEP", -1},
+	1:   {"OPEN", -1},
+	2:   {"OPEN.LINKS", -1},
+	3:   {"CLOSE.ALL", -1},
+	4:   {"SAVE", -1},
+	5:   {"SAVE.AS", -1},
+	6:   {"FILE.DELETE", -1},
+	7:   {"PAGE.SETUP", -1},
+	8:   {"PRINT", -1},
+	9:   {"PRINTER.SETUP", -1},
+	10:  {"QUIT", -1},
+	11:  {"NEW.WINDOW", -1},
+	12:  {"ARRANGE.ALL", -1},
+	13:  {"WINDOW.SIZE", -1},
+	14:  {"WINDOW.MOVE", -1},
+	15:  {"FULL", -1},
+	16:  {"CLOSE", -1},
+	17:  {"RUN", -1},
+	22:  {"SET.PRINT.AREA", -1},
+	23:  {"SET.PRINT.TITLES", -1},
+	24:  {"SET.PAGE.BREAK", -1},
+	25:  {"REMOVE.PAGE.BREAK", -1},
+	26:  {"FONT", -1},
+	27:  {"DISPLAY", -1},
+	28:  {"PROTECT.DOCUMENT", -1},
+	29:  {"PRECISION", -1},
+	30:  {"A1.R1C1", -1},
+	31:  {"CALCULATE.NOW", 0},
+	32:  {"CALCULATION", -1},
+	34:  {"DATA.FIND", -1},
+	35:  {"EXTRACT", -1},
+	36:  {"DATA.DELETE", -1},
+	37:  {"SET.DATABASE", -1},
+	38:  {"SET.CRITERIA", -1},
+	39:  {"SORT", -1},
+	40:  {"DATA.SERIES", -1},
+	41:  {"TABLE", -1},
+	42:  {"FORMAT.NUMBER", -1},
+	43:  {"ALIGNMENT", -1},
+	44:  {"STYLE", -1},
+	45:  {"BORDER", -1},
+	46:  {"CELL.PROTECTION", -1},
+	47:  {"COLUMN.WIDTH", -1},
+	48:  {"UNDO", 0},
+	49:  {"CUT", -1},
+	50:  {"COPY", -1},
+	51:  {"PASTE", -1},
+	52:  {"CLEAR", -1},
+	53:  {"PASTE.SPECIAL", -1},
+	54:  {"EDIT.DELETE", -1},
+	55:  {"INSERT", -1},
+	56:  {"FILL.RIGHT", 0},
+	57:  {"FILL.DOWN", 0},
+	61:  {"DEFINE.NAME", -1},
+	62:  {"CREATE.NAMES", -1},
+	63:  {"FORMULA.GOTO", -1},
+	64:  {"FORMULA.FIND", -1},
+	65:  {"SELECT.LAST.CELL", 0},
+	66:  {"SHOW.ACTIVE.CELL", 0},
+	67:  {"GALLERY.AREA", -1},
+	68:  {"GALLERY.BAR", -1},
+	69:  {"GALLERY.COLUMN", -1},
+	70:  {"GALLERY.LINE", -1},
+	71:  {"GALLERY.PIE", -1},
+	72:  {"GALLERY.SCATTER", -1},
+	73:  {"COMBINATION", -1},
+	74:  {"PREFERRED", -1},
+	75:  {"ADD.OVERLAY", -1},
+	76:  {"GRIDLINES", -1},
+	77:  {"SET.PREFERRED", -1},
+	78:  {"AXES", -1},
+	79:  {"LEGEND", -1},
+	80:  {"ATTACH.TEXT", -1},
+	81:  {"ADD.ARROW", -1},
+	82:  {"SELECT.CHART", 0},
+	83:  {"SELECT.PLOT.AREA", 0},
+	84:  {"PATTERNS", -1},
+	85:  {"MAIN.CHART", -1},
+	86:  {"OVERLAY", -1},
+	87:  {"SCALE", -1},
+	88:  {"FORMAT.LEGEND", -1},
+	89:  {"FORMAT.TEXT", -1},
+	90:  {"EDIT.REPEAT", 0},
+	91:  {"PARSE", -1},
+	92:  {"JUSTIFY", 0},
+	93:  {"HIDE", 0},
+	94:  {"UNHIDE", -1},
+	95:  {"WORKSPACE", -1},
+	96:  {"FORMULA", -1},
+	97:  {"FORMULA.FILL", -1},
+	98:  {"FORMULA.ARRAY", -1},
+	99:  {"DATA.FIND.NEXT", 0},
+	100: {"DATA.FIND.PREV", 0},
+	101: {"FORMULA.FIND.NEXT", 0},
+	102: {"FORMULA.FIND.PREV", 0},
+	103: {"ACTIVATE", -1},
+	104: {"ACTIVATE.NEXT", -1},
+	105: {"ACTIVATE.PREV", -1},
+	106: {"UNLOCKED.NEXT", 0},
+	107: {"UNLOCKED.PREV", 0},
+	108: {"COPY.PICTURE", -1},
+	109: {"SELECT", -1},
+	110: {"DELETE.NAME", -1},
+	111: {"DELETE.FORMAT", -1},
+	112: {"VLINE", -1},
+	113: {"HLINE", -1},
+	114: {"VPAGE", -1},
+	115: {"HPAGE", -1},
+	116: {"VSCROLL", -1},
+	117: {"HSCROLL", -1},
+	118: {"ALERT", -1},
+	119: {"NEW", -1},
+	120: {"CANCEL.COPY", 0},
+	121: {"SHOW.CLIPBOARD", 0},
+	122: {"MESSAGE", -1},
+	124: {"PASTE.LINK", -1},
+	125: {"APP.ACTIVATE", -1},
+	126: {"DELETE.ARROW", 0},
+	127: {"ROW.HEIGHT", -1},
+	128: {"FORMAT.MOVE", -1},
+	129: {"FORMAT.SIZE", -1},
+	130: {"FORMULA.REPLACE", -1},
+	131: {"SEND.KEYS", -1},
+	132: {"SELECT.SPECIAL", -1},
+	133: {"APPLY.NAMES", -1},
+	134: {"REPLACE.FONT", -1},
+	135: {"FREEZE.PANES", -1},
+	136: {"SHOW.INFO", -1},
+	137: {"SPLIT", -1},
+	138: {"ON.WINDOW", -1},
+	139: {"ON.DATA", -1},
+	140: {"DISABLE.INPUT", -1},
+	142: {"OUTLINE", -1},
+	143: {"LIST.NAMES", -1},
+	144: {"FILE.CLOSE", -1},
+	145: {"SAVE.WORKBOOK", -1},
+	146: {"DATA.FORM", -1},
+	147: {"COPY.CHART", -1},
+	148: {"ON.TIME", -1},
+	149: {"WAIT", -1},
+	150: {"FORMAT.FONT", -1},
+	151: {"FILL.UP", 0},
+	152: {"FILL.LEFT", 0},
+	153: {"DELETE.OVERLAY", 0},
+	155: {"SHORT.MENUS", -1},
+	159: {"SET.UPDATE.STATUS", -1},
+	161: {"COLOR.PALETTE", -1},
+	162: {"DELETE.STYLE", -1},
+	163: {"WINDOW.RESTORE", -1},
+	164: {"WINDOW.MAXIMIZE", -1},
+	166: {"CHANGE.LINK", -1},
+	167: {"CALCULATE.DOCUMENT", 0},
+	168: {"ON.KEY", -1},
+	169: {"APP.RESTORE", 0},
+	170: {"APP.MOVE", -1},
+	171: {"APP.SIZE", -1},
+	172: {"APP.MINIMIZE", 0},
+	173: {"APP.MAXIMIZE", 0},
+	174: {"BRING.TO.FRONT", 0},
+	175: {"SEND.TO.BACK", 0},
+	185: {"MAIN.CHART.TYPE", -1},
+	186: {"OVERLAY.CHART.TYPE", -1},
+	187: {"SELECT.END", -1},
+	188: {"OPEN.MAIL", -1},
+	189: {"SEND.MAIL", -1},
+	190: {"STANDARD.FONT", -1},
+	191: {"CONSOLIDATE", -1},
+	192: {"SORT.SPECIAL", -1},
+	193: {"GALLERY.3D.AREA", -1},
+	194: {"GALLERY.3D.COLUMN", -1},
+	195: {"GALLERY.3D.LINE", -1},
+	196: {"GALLERY.3D.PIE", -1},
+	197: {"VIEW.3D", -1},
+	198: {"GOAL.SEEK", -1},
+	199: {"WORKGROUP", -1},
+	200: {"FILL.GROUP", -1},
+	201: {"UPDATE.LINK", -1},
+	202: {"PROMOTE", -1},
+	203: {"DEMOTE", -1},
+	204: {"SHOW.DETAIL", -1},
+	206: {"UNGROUP", 0},
+	207: {"OBJECT.PROPERTIES", -1},
+	208: {"SAVE.NEW.OBJECT", -1},
+	209: {"SHARE", -1},
+	210: {"SHARE.NAME", -1},
+	211: {"DUPLICATE", 0},
+	212: {"APPLY.STYLE", -1},
+	213: {"ASSIGN.TO.OBJECT", -1},
+	214: {"OBJECT.PROTECTION", -1},
+	215: {"HIDE.OBJECT", -1},
+	216: {"SET.EXTRACT", -1},
+	217: {"CREATE.PUBLISHER", -1},
+	218: {"SUBSCRIBE.TO", -1},
+	219: {"ATTRIBUTES", -1},
+	220: {"SHOW.TOOLBAR", -1},
+	222: {"PRINT.PREVIEW", -1},
+	223: {"EDIT.COLOR", -1},
+	224: {"SHOW.LEVELS", -1},
+	225: {"FORMAT.MAIN", -1},
+	226: {"FORMAT.OVERLAY", -1},
+	227: {"ON.RECALC", -1},
+	228: {"EDIT.SERIES", -1},
+	229: {"DEFINE.STYLE", -1},
+	240: {"LINE.PRINT", -1},
+	243: {"ENTER.DATA", -1},
+	249: {"GALLERY.RADAR", -1},
+	250: {"MERGE.STYLES", -1},
+	251: {"EDITION.OPTIONS", -1},
+	252: {"PASTE.PICTURE", -1},
+	253: {"PASTE.PICTURE.LINK", -1},
+	254: {"SPELLING", -1},
+	256: {"ZOOM", -1},
+	259: {"INSERT.OBJECT", -1},
+	260: {"WINDOW.MINIMIZE", -1},
+	265: {"SOUND.NOTE", -1},
+	266: {"SOUND.PLAY", -1},
+	267: {"FORMAT.SHAPE", -1},
+	268: {"EXTEND.POLYGON", -1},
+	269: {"FORMAT.AUTO", -1},
+	272: {"GALLERY.3D.BAR", -1},
+	273: {"GALLERY.3D.SURFACE", -1},
+	274: {"FILL.AUTO", -1},
+	276: {"CUSTOMIZE.TOOLBAR", -1},
+	277: {"ADD.TOOL", -1},
+	278: {"EDIT.OBJECT", -1},
+	279: {"ON.DOUBLECLICK", -1},
+	280: {"ON.ENTRY", -1},
+	281: {"WORKBOOK.ADD", -1},
+	282: {"WORKBOOK.MOVE", -1},
+	283: {"WORKBOOK.COPY", -1},
+	284: {"WORKBOOK.OPTIONS", -1},
+	285: {"SAVE.WORKSPACE", -1},
+	288: {"CHART.WIZARD", -1},
+	289: {"DELETE.TOOL", -1},
+	290: {"MOVE.TOOL", -1},
+	291: {"WORKBOOK.SELECT", -1},
+	292: {"WORKBOOK.ACTIVATE", -1},
+	293: {"ASSIGN.TO.TOOL", -1},
+	295: {"COPY.TOOL", -1},
+	296: {"RESET.TOOL", -1},
+	297: {"CONSTRAIN.NUMERIC", -1},
+	298: {"PASTE.TOOL", -1},
+	302: {"WORKBOOK.NEW", -1},
+	305: {"SCENARIO.CELLS", -1},
+	306: {"SCENARIO.DELETE", -1},
+	307: {"SCENARIO.ADD", -1},
+	308: {"SCENARIO.EDIT", -1},
+	309: {"SCENARIO.SHOW", -1},
+	310: {"SCENARIO.SHOW.NEXT", -1},
+	311: {"SCENARIO.SUMMARY", -1},
+	312: {"PIVOT.TABLE.WIZARD", -1},
+	313: {"PIVOT.FIELD.PROPERTIES", -1},
+	314: {"PIVOT.FIELD", -1},
+	315: {"PIVOT.ITEM", -1},
+	316: {"PIVOT.ADD.FIELDS", -1},
+	318: {"OPTIONS.CALCULATION", -1},
+	319: {"OPTIONS.EDIT", -1},
+	320: {"OPTIONS.VIEW", -1},
+	321: {"ADDIN.MANAGER", -1},
+	322: {"MENU.EDITOR", -1},
+	323: {"ATTACH.TOOLBARS", -1},
+	324: {"VBAACTIVATE", -1},
+	325: {"OPTIONS.CHART", -1},
+	328: {"VBA.INSERT.FILE", -1},
+	330: {"VBA.PROCEDURE.DEFINITION", -1},
+	336: {"ROUTING.SLIP", -1},
+	338: {"ROUTE.DOCUMENT", -1},
+	339: {"MAIL.LOGON", -1},
+	342: {"INSERT.PICTURE", -1},
+	343: {"EDIT.TOOL", -1},
+	344: {"GALLERY.DOUGHNUT", -1},
+	350: {"CHART.TREND", -1},
+	352: {"PIVOT.ITEM.PROPERTIES", -1},
+	354: {"WORKBOOK.INSERT", -1},
+	355: {"OPTIONS.TRANSITION", -1},
+	356: {"OPTIONS.GENERAL", -1},
+	370: {"FILTER.ADVANCED", -1},
+	373: {"MAIL.ADD.MAILER", -1},
+	374: {"MAIL.DELETE.MAILER", -1},
+	375: {"MAIL.REPLY", -1},
+	376: {"MAIL.REPLY.ALL", -1},
+	377: {"MAIL.FORWARD", -1},
+	378: {"MAIL.NEXT.LETTER", -1},
+	379: {"DATA.LABEL", -1},
+	380: {"INSERT.TITLE", -1},
+	381: {"FONT.PROPERTIES", -1},
+	382: {"MACRO.OPTIONS", -1},
+	383: {"WORKBOOK.HIDE", -1},
+	384: {"WORKBOOK.UNHIDE", -1},
+	385: {"WORKBOOK.DELETE", -1},
+	386: {"WORKBOOK.NAME", -1},
+	388: {"GALLERY.CUSTOM", -1},
+	390: {"ADD.CHART.AUTOFORMAT", -1},
+	391: {"DELETE.CHART.AUTOFORMAT", -1},
+	392: {"CHART.ADD.DATA", -1},
+	393: {"AUTO.OUTLINE", -1},
+	394: {"TAB.ORDER", -1},
+	395: {"SHOW.DIALOG", -1},
+	396: {"SELECT.ALL", -1},
+	397: {"UNGROUP.SHEETS", -1},
+	398: {"SUBTOTAL.CREATE", -1},
+	399: {"SUBTOTAL.REMOVE", -1},
+	400: {"RENAME.OBJECT", -1},
+	412: {"WORKBOOK.SCROLL", -1},
+	413: {"WORKBOOK.NEXT", -1},
+	414: {"WORKBOOK.PREV", -1},
+	415: {"WORKBOOK.TAB.SPLIT", -1},
+	416: {"FULL.SCREEN", -1},
+	417: {"WORKBOOK.PROTECT", -1},
+	420: {"SCROLLBAR.PROPERTIES", -1},
+	421: {"PIVOT.SHOW.PAGES", -1},
+	422: {"TEXT.TO.COLUMNS", -1},
+	423: {"FORMAT.CHARTTYPE", -1},
+	424: {"LINK.FORMAT", -1},
+	425: {"TRACER.DISPLAY", -1},
+	430: {"TRACER.NAVIGATE", -1},
+	431: {"TRACER.CLEAR", -1},
+	432: {"TRACER.ERROR", -1},
+	433: {"PIVOT.FIELD.GROUP", -1},
+	434: {"PIVOT.FIELD.UNGROUP", -1},
+	435: {"CHECKBOX.PROPERTIES", -1},
+	436: {"LABEL.PROPERTIES", -1},
+	437: {"LISTBOX.PROPERTIES", -1},
+	438: {"EDITBOX.PROPERTIES", -1},
+	439: {"PIVOT.REFRESH", -1},
+	440: {"LINK.COMBO", -1},
+	441: {"OPEN.TEXT", -1},
+	442: {"HIDE.DIALOG", -1},
+	443: {"SET.DIALOG.FOCUS", -1},
+	444: {"ENABLE.OBJECT", -1},
+	445: {"PUSHBUTTON.PROPERTIES", -1},
+	446: {"SET.DIALOG.DEFAULT", -1},
+	447: {"FILTER", -1},
+	448: {"FILTER.SHOW.ALL", -1},
+	449: {"CLEAR.OUTLINE", -1},
+	450: {"FUNCTION.WIZARD", -1},
+	451: {"ADD.LIST.ITEM", -1},
+	452: {"SET.LIST.ITEM", -1},
+	453: {"REMOVE.LIST.ITEM", -1},
+	454: {"SELECT.LIST.ITEM", -1},
+	455: {"SET.CONTROL.VALUE", -1},
+	456: {"SAVE.COPY.AS", -1},
+	458: {"OPTIONS.LISTS.ADD", -1},
+	459: {"OPTIONS.LISTS.DELETE", -1},
+	460: {"SERIES.AXES", -1},
+	461: {"SERIES.X", -1},
+	462: {"SERIES.Y", -1},
+	463: {"ERRORBAR.X", -1},
+	464: {"ERRORBAR.Y", -1},
+	465: {"FORMAT.CHART", -1},
+	466: {"SERIES.ORDER", -1},
+	467: {"MAIL.LOGOFF", -1},
+	468: {"CLEAR.ROUTING.SLIP", -1},
+	469: {"APP.ACTIVATE.MICROSOFT", -1},
+	470: {"MAIL.EDIT.MAILER", -1},
+	471: {"ON.SHEET", -1},
+	472: {"STANDARD.WIDTH", -1},
+	473: {"SCENARIO.MERGE", -1},
+	474: {"SUMMARY.INFO", -1},
+	475: {"FIND.FILE", -1},
+	476: {"ACTIVE.CELL.FONT", -1},
+	477: {"ENABLE.TIPWIZARD", -1},
+	478: {"VBA.MAKE.ADDIN", -1},
+	480: {"INSERTDATATABLE", -1},
+	481: {"WORKGROUP.OPTIONS", -1},
+	482: {"MAIL.SEND.MAILER", -1},
+	485: {"AUTOCORRECT", -1},
+	489: {"POST.DOCUMENT", -1},
+	491: {"PICKLIST", -1},
+	493: {"VIEW.SHOW", -1},
+	494: {"VIEW.DEFINE", -1},
+	495: {"VIEW.DELETE", -1},
+	509: {"SHEET.BACKGROUND", -1},
+	510: {"INSERT.MAP.OBJECT", -1},
+	511: {"OPTIONS.MENONO", -1},
+	517: {"MSOCHECKS", -1},
+	518: {"NORMAL", -1},
+	519: {"LAYOUT", -1},
+	520: {"RM.PRINT.AREA", -1},
+	521: {"CLEAR.PRINT.AREA", -1},
+	522: {"ADD.PRINT.AREA", -1},
+	523: {"MOVE.BRK", -1},
+	545: {"HIDECURR.NOTE", -1},
+	546: {"HIDEALL.NOTES", -1},
+	547: {"DELETE.NOTE", -1},
+	548: {"TRAVERSE.NOTES", -1},
+	549: {"ACTIVATE.NOTES", -1},
+	620: {"PROTECT.REVISIONS", -1},
+	621: {"UNPROTECT.REVISIONS", -1},
+	647: {"OPTIONS.ME", -1},
+	653: {"WEB.PUBLISH", -1},
+	667: {"NEWWEBQUERY", -1},
+	673: {"PIVOT.TABLE.CHART", -1},
+	753: {"OPTIONS.SAVE", -1},
+	755: {"OPTIONS.SPELL", -1},
+	808: {"HIDEALL.INKANNOTS", -1},
+}
+
+// command returns a macro command, with a CMD placeholder name and a
+// variable argument count for an index missing from the command table.
+func command(id uint16) funcInfo {
+	if f, ok := commands[id]; ok {
+		return f
+	}
+	return funcInfo{"CMD" + strconv.Itoa(int(id)), -1}
+}
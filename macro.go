@@ -0,0 +1,77 @@
+package xls
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// MacroCell is a formula of an Excel 4.0 macro sheet.
+type MacroCell struct {
+	Row int
+	Col int
+	// Formula is the formula text without the leading "=", empty if it
+	// cannot be decoded.
+	Formula string
+}
+
+// MacroSheet holds the formulas of an Excel 4.0 macro sheet.
+type MacroSheet struct {
+	Name       string
+	Visibility TWorkSheetVisibility
+	Cells      []MacroCell
+}
+
+// MacroReport lists the Excel 4.0 macros of a workbook.
+type MacroReport struct {
+	Sheets []MacroSheet
+	// AutoRun holds the names that run macros when the workbook is
+	// opened, closed, activated or deactivated.
+	AutoRun []DefinedName
+}
+
+// autoRunNames are the name prefixes Excel runs as macros.
+var autoRunNames = []string{NameAutoOpen, NameAutoClose, NameAutoActivate, NameAutoDeactivate}
+
+// macroCell decodes the formula of a FORMULA record of a macro sheet.
+func (w *WorkSheet) macroCell(c *FormulaCol) MacroCell {
+	m := MacroCell{Row: int(c.Header.RowB), Col: int(c.Header.FirstColB)}
+	if w.wb.Is5ver || len(c.Bts) < 2 {
+		return m
+	}
+	cce := int(binary.LittleEndian.Uint16(c.Bts))
+	if cce > len(c.Bts)-2 {
+		return m
+	}
+	m.Formula, _ = formulaText(c.Bts[2:2+cce], c.Bts[2+cce:], cellRef{m.Row, m.Col}, w.wb)
+	return m
+}
+
+// Macros reads the macro sheets of the workbook and reports their formulas
+// and the names that run them automatically.
+func (w *WorkBook) Macros() (*MacroReport, error) {
+	r := new(MacroReport)
+	for i, s := range w.sheets {
+		if s.Type != SheetMacro {
+			continue
+		}
+		s, err := w.GetSheet(i)
+		if err != nil {
+			return nil, err
+		}
+		r.Sheets = append(r.Sheets, MacroSheet{
+			Name:       s.Name,
+			Visibility: s.Visibility,
+			Cells:      s.Macros,
+		})
+	}
+	for _, n := range w.Names {
+		for _, prefix := range autoRunNames {
+			// Excel also runs names such as Auto_Open2.
+			if len(n.Name) >= len(prefix) && strings.EqualFold(n.Name[:len(prefix)], prefix) {
+				r.AutoRun = append(r.AutoRun, n)
+				break
+			}
+		}
+	}
+	return r, nil
+}
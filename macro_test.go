@@ -0,0 +1,74 @@
+package xls
+
+import (
+	"bytes"
+	"testing"
+)
+
+func formulaRecord(row, col uint16, rgce []byte) []byte {
+	return biffRecord(0x06, cat(le16(row, col, 0), make([]byte, 8), le16(0), le32(0), le16(uint16(len(rgce))), rgce))
+}
+
+func TestMacros(t *testing.T) {
+	exec := cat(ptgStr("calc.exe"), []byte{0x42, 1}, le16(110))
+	run := cat([]byte{0x44}, le16(4, 0xC000), []byte{0x42, 1}, le16(0x8000|17))
+	stream := cat(
+		biffRecord(0x809, cat(le16(0x600, 0x40), make([]byte, 12))),
+		formulaRecord(0, 0, exec),
+		biffRecord(0x207, cat(le16(1), []byte{0}, []byte("1"))),
+		formulaRecord(1, 0, run),
+		formulaRecord(2, 0, cat([]byte{0x42, 0}, le16(54))),
+		formulaRecord(3, 0, cat([]byte{0x42, 0}, le16(0x8000|0x7FF))),
+		formulaRecord(4, 0, cat(ptgStr("=EXEC(1)"), []byte{0x44}, le16(0, 0xC001), []byte{0x42, 2}, le16(0x8000|96))),
+		formulaRecord(5, 0, cat(ptgStr("Done"), []byte{0x42, 1}, le16(0x8000|118))),
+		formulaRecord(6, 0, cat(ptgNum(1), []byte{0x42, 1}, le16(0x8000|149))),
+		formulaRecord(7, 0, cat(ptgNum(1), ptgStr("Run"), []byte{0x42, 2}, le16(0x8000|148))),
+		formulaRecord(8, 0, cat(ptgStr("Book1"), []byte{0x42, 1}, le16(0x8000|383))),
+		formulaRecord(9, 0, cat([]byte{0x21}, le16(0x8000|31))),
+		// BEEP takes an optional argument, the count is unknown.
+		formulaRecord(10, 0, cat(ptgNum(1), []byte{0x21}, le16(0x8000))),
+		biffRecord(0x0A, nil),
+	)
+	wb := &WorkBook{Formats: make(map[uint16]*Format), rs: bytes.NewReader(stream)}
+	wb.sheets = []*WorkSheet{
+		{wb: wb, bs: &boundsheet{}, Name: "Data", Type: SheetWorksheet},
+		{wb: wb, bs: &boundsheet{}, Name: "Macro1", Type: SheetMacro, Visibility: WorkSheetVeryHidden},
+	}
+	wb.Names = []DefinedName{
+		{Name: NameAutoOpen, Builtin: true, Sheet: -1},
+		{Name: "auto_open_x", Sheet: -1},
+		{Name: "Totals", Sheet: -1},
+	}
+	r, err := wb.Macros()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Sheets) != 1 || r.Sheets[0].Name != "Macro1" || r.Sheets[0].Visibility != WorkSheetVeryHidden {
+		t.Fatalf("got sheets %+v", r.Sheets)
+	}
+	want := []MacroCell{
+		{0, 0, `EXEC("calc.exe")`},
+		{1, 0, "RUN(A5)"},
+		{2, 0, "HALT()"},
+		{3, 0, "CMD2047()"},
+		{4, 0, `FORMULA("=EXEC(1)",B1)`},
+		{5, 0, `ALERT("Done")`},
+		{6, 0, "WAIT(1)"},
+		{7, 0, `ON.TIME(1,"Run")`},
+		{8, 0, `WORKBOOK.HIDE("Book1")`},
+		{9, 0, "CALCULATE.NOW()"},
+		{10, 0, ""},
+	}
+	cells := r.Sheets[0].Cells
+	if len(cells) != len(want) {
+		t.Fatalf("got cells %+v", cells)
+	}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Errorf("cell %d = %+v, want %+v", i, cells[i], want[i])
+		}
+	}
+	if len(r.AutoRun) != 2 || r.AutoRun[0].Name != NameAutoOpen || r.AutoRun[1].Name != "auto_open_x" {
+		t.Errorf("got auto run names %+v", r.AutoRun)
+	}
+}
@@ -0,0 +1,126 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// Built-in names, stored as a single character code in NAME records.
+const (
+	NameConsolidateArea = "Consolidate_Area"
+	NameAutoOpen        = "Auto_Open"
+	NameAutoClose       = "Auto_Close"
+	NameExtract         = "Extract"
+	NameDatabase        = "Database"
+	NameCriteria        = "Criteria"
+	NamePrintArea       = "Print_Area"
+	NamePrintTitles     = "Print_Titles"
+	NameRecorder        = "Recorder"
+	NameDataForm        = "Data_Form"
+	NameAutoActivate    = "Auto_Activate"
+	NameAutoDeactivate  = "Auto_Deactivate"
+	NameSheetTitle      = "Sheet_Title"
+	NameFilterDatabase  = "_FilterDatabase"
+)
+
+var builtinNames = []string{
+	NameConsolidateArea, NameAutoOpen, NameAutoClose, NameExtract, NameDatabase,
+	NameCriteria, NamePrintArea, NamePrintTitles, NameRecorder, NameDataForm,
+	NameAutoActivate, NameAutoDeactivate, NameSheetTitle, NameFilterDatabase,
+}
+
+// DefinedName is a name defined in the workbook.
+type DefinedName struct {
+	Name     string
+	Hidden   bool
+	Function bool // A macro function or command.
+	Builtin  bool
+	// Sheet is the zero-based index of the sheet the name is local to, -1
+	// for names of the workbook.
+	Sheet   int
+	Formula string
+
+	rgce  []byte
+	extra []byte
+}
+
+type nameInfo struct {
+	Flags    uint16
+	Key      byte
+	NameSize byte
+	Size     uint16
+	_        uint16
+	Sheet    uint16
+	_        uint32
+}
+
+// parseName reads a NAME record.
+func (w *WorkBook) parseName(bts []byte) {
+	buf := bytes.NewReader(bts)
+	var info nameInfo
+	if binary.Read(buf, binary.LittleEndian, &info) != nil {
+		return
+	}
+	n := DefinedName{
+		Hidden:   info.Flags&0x1 != 0,
+		Function: info.Flags&0x2 != 0,
+		Builtin:  info.Flags&0x20 != 0,
+		Sheet:    int(info.Sheet) - 1,
+	}
+	if w.Is5ver {
+		// The BIFF5 name is not preceded by a flag byte.
+		name := make([]byte, info.NameSize)
+		io.ReadFull(buf, name)
+		n.Name = decodeWindows1251(name)
+	} else {
+		n.Name, _ = w.getString(buf, uint16(info.NameSize))
+	}
+	if n.Builtin && len(n.Name) == 1 && int(n.Name[0]) < len(builtinNames) {
+		n.Name = builtinNames[n.Name[0]]
+	}
+	n.rgce = make([]byte, info.Size)
+	k, _ := io.ReadFull(buf, n.rgce)
+	n.rgce = n.rgce[:k]
+	n.extra = bts[len(bts)-buf.Len():]
+	w.Names = append(w.Names, n)
+}
+
+// resolveNames decodes the formulas of the names once all records of the
+// workbook globals are read.
+func (w *WorkBook) resolveNames() {
+	if w.Is5ver {
+		return
+	}
+	for i := range w.Names {
+		n := &w.Names[i]
+		n.Formula, _ = formulaText(n.rgce, n.extra, cellRef{}, w)
+	}
+}
+
+// definedName returns the name at a one-based NAME record index.
+func (w *WorkBook) definedName(idx uint32) string {
+	if idx == 0 || int(idx) > len(w.Names) {
+		return "#NAME?"
+	}
+	return w.Names[idx-1].Name
+}
+
+// Name returns the name local to sheet, or else the workbook name.
+func (w *WorkBook) Name(name string, sheet int) (DefinedName, bool) {
+	found := -1
+	for i, n := range w.Names {
+		switch {
+		case !strings.EqualFold(n.Name, name):
+		case n.Sheet == sheet:
+			return n, true
+		case n.Sheet == -1:
+			found = i
+		}
+	}
+	if found < 0 {
+		return DefinedName{}, false
+	}
+	return w.Names[found], true
+}
@@ -1,6 +1,8 @@
 package ole2
 
 import (
+	"fmt"
+	"time"
 	"unicode/utf16"
 )
 
@@ -13,6 +15,9 @@ const (
 	ROOT        = iota
 )
 
+// NOSTREAM marks a missing sibling or child in the directory tree.
+var NOSTREAM = uint32(0xFFFFFFFF)
+
 type File struct {
 	NameBts   [32]uint16
 	Bsize     uint16
@@ -30,6 +35,60 @@ type File struct {
 }
 
 func (d *File) Name() string {
-	runes := utf16.Decode(d.NameBts[:d.Bsize/2-1])
+	n := int(d.Bsize/2) - 1
+	if n < 0 {
+		n = 0
+	}
+	if n > len(d.NameBts) {
+		n = len(d.NameBts)
+	}
+	runes := utf16.Decode(d.NameBts[:n])
 	return string(runes)
 }
+
+// CLSID of a storage, the zero GUID if not set.
+func (d *File) CLSID() GUID {
+	var g GUID
+	for i, v := range d.Guid {
+		g[2*i] = byte(v)
+		g[2*i+1] = byte(v >> 8)
+	}
+	return g
+}
+
+// Created time of a storage, zero if not recorded.
+func (d *File) Created() time.Time {
+	return filetime(d.Time[0])
+}
+
+// Modified time of a storage, zero if not recorded.
+func (d *File) Modified() time.Time {
+	return filetime(d.Time[1])
+}
+
+// filetime converts a Windows FILETIME, 100ns intervals since 1601.
+func filetime(ft uint64) time.Time {
+	if ft == 0 {
+		return time.Time{}
+	}
+	const epochDiff = 116444736000000000 // 1601 to 1970 in 100ns.
+	if ft < epochDiff {
+		return time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(ft/10000) * time.Millisecond)
+	}
+	ft -= epochDiff
+	return time.Unix(int64(ft/1e7), int64(ft%1e7)*100).UTC()
+}
+
+// GUID as stored on disk, the first three groups are little endian.
+type GUID [16]byte
+
+func (g GUID) String() string {
+	return fmt.Sprintf("{%02X%02X%02X%02X-%02X%02X-%02X%02X-%02X%02X-%02X%02X%02X%02X%02X%02X}",
+		g[3], g[2], g[1], g[0], g[5], g[4], g[7], g[6],
+		g[8], g[9], g[10], g[11], g[12], g[13], g[14], g[15])
+}
+
+// IsZero reports if no GUID is set.
+func (g GUID) IsZero() bool {
+	return g == GUID{}
+}
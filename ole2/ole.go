@@ -2,6 +2,7 @@ package ole2
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -23,11 +24,19 @@ func Open(reader io.ReadSeeker, charset string) (ole *Ole, err error) {
 	var hbts = make([]byte, 512)
 	reader.Read(hbts)
 	if header, err = parseHeader(hbts); err == nil {
+		// Version 3 files use 512 byte sectors, version 4 files use 4096 byte sectors.
+		// Both store the shift so honour it rather than assume a size.
+		if header.Lsectorb < 7 || header.Lsectorb > 16 {
+			return nil, fmt.Errorf("invalid sector shift %d", header.Lsectorb)
+		}
+		if header.Lssectorb < 2 || header.Lssectorb > header.Lsectorb {
+			return nil, fmt.Errorf("invalid short sector shift %d", header.Lssectorb)
+		}
 		ole = new(Ole)
 		ole.reader = reader
 		ole.header = header
-		ole.Lsector = 512 //TODO
-		ole.Lssector = 64 //TODO
+		ole.Lsector = 1 << header.Lsectorb
+		ole.Lssector = 1 << header.Lssectorb
 		err = ole.readMSAT()
 		return ole, err
 	}
@@ -80,9 +89,14 @@ func (o *Ole) readMSAT() error {
 		}
 	}
 
-	for sid := o.header.Difstart; sid != ENDOFCHAIN; {
+	remain := o.header.Cfat - count
+	for sid := o.header.Difstart; sid != ENDOFCHAIN && sid != FREESECT && remain > 0; {
 		if sector, err := o.sector_read(sid); err == nil {
 			sids := sector.MsatValues(o.Lsector)
+			if uint32(len(sids)) > remain {
+				sids = sids[:remain]
+			}
+			remain -= uint32(len(sids))
 
 			for _, sid := range sids {
 				if sector, err := o.sector_read(sid); err == nil {
@@ -100,20 +114,20 @@ func (o *Ole) readMSAT() error {
 		}
 	}
 
-	for i := uint32(0); i < o.header.Csfat; i++ {
-		sid := o.header.Sfatstart
-
-		if sid != ENDOFCHAIN {
-			if sector, err := o.sector_read(sid); err == nil {
-				sids := sector.MsatValues(o.Lsector)
-
-				o.SSecID = append(o.SSecID, sids...)
+	// The short-sector allocation table is a regular stream, follow its chain in the SAT.
+	sid := o.header.Sfatstart
+	for i := uint32(0); i < o.header.Csfat && sid != ENDOFCHAIN; i++ {
+		if sector, err := o.sector_read(sid); err == nil {
+			sids := sector.AllValues(o.Lsector)
 
-				sid = sector.NextSid(o.Lsector)
-			} else {
-				return err
-			}
+			o.SSecID = append(o.SSecID, sids...)
+		} else {
+			return err
+		}
+		if sid >= uint32(len(o.SecID)) {
+			return fmt.Errorf("short sector allocation table sector %d out of range", sid)
 		}
+		sid = o.SecID[sid]
 	}
 	return nil
 
@@ -147,8 +161,10 @@ func (o *Ole) sector_read_internal(sid, size uint32) (Sector, error) {
 	}
 }
 
+// sector_pos returns the file offset of a sector. The header is padded to a
+// full sector, so sector 0 starts one sector into the file.
 func sector_pos(sid uint32, size uint32) uint32 {
-	return 512 + sid*size
+	return (sid + 1) * size
 }
 
 func short_sector_pos(sid uint32, size uint32) uint32 {
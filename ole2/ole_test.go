@@ -0,0 +1,103 @@
+package ole2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"unicode/utf16"
+)
+
+func dirEntry(name string, typ byte, child, start, size uint32) File {
+	f := File{
+		Type:   typ,
+		Left:   FREESECT,
+		Right:  FREESECT,
+		Child:  child,
+		Sstart: start,
+		Size:   size,
+	}
+	n := utf16.Encode([]rune(name))
+	copy(f.NameBts[:], n)
+	f.Bsize = uint16(len(n)+1) * 2
+	return f
+}
+
+// TestOpenVersion4 reads a hand built version 4 file with 4096 byte sectors.
+func TestOpenVersion4(t *testing.T) {
+	const lsector = 4096
+	const size = 5000
+
+	header := &Header{
+		ID:           [2]uint32{0xE011CFD0, 0xE11AB1A1},
+		Verminor:     0x3E,
+		Verdll:       4,
+		ByteOrder:    0xFFFE,
+		Lsectorb:     12,
+		Lssectorb:    6,
+		Cfat:         1,
+		Dirstart:     1,
+		Sectorcutoff: 4096,
+		Sfatstart:    ENDOFCHAIN,
+		Difstart:     ENDOFCHAIN,
+	}
+	for i := range header.Msat {
+		header.Msat[i] = FREESECT
+	}
+	header.Msat[0] = 0
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, header)
+	buf.Write(make([]byte, lsector-buf.Len()))
+
+	// Sector 0: FAT.
+	fat := make([]uint32, lsector/4)
+	for i := range fat {
+		fat[i] = FREESECT
+	}
+	fat[0] = 0xFFFFFFFD
+	fat[1] = ENDOFCHAIN
+	fat[2] = 3
+	fat[3] = ENDOFCHAIN
+	binary.Write(buf, binary.LittleEndian, fat)
+
+	// Sector 1: directory.
+	dir := []File{
+		dirEntry("Root Entry", ROOT, 1, ENDOFCHAIN, 0),
+		dirEntry("Workbook", USERSTREAM, FREESECT, 2, size),
+	}
+	binary.Write(buf, binary.LittleEndian, dir)
+	buf.Write(make([]byte, lsector-len(dir)*128))
+
+	// Sectors 2 and 3: stream data.
+	data := make([]byte, 2*lsector)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	buf.Write(data)
+
+	ole, err := Open(bytes.NewReader(buf.Bytes()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ole.Lsector != lsector || ole.Lssector != 64 {
+		t.Fatalf("got sector sizes %d/%d", ole.Lsector, ole.Lssector)
+	}
+	files, err := ole.ListDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d directory entries, want 2", len(files))
+	}
+	if name := files[1].Name(); name != "Workbook" {
+		t.Fatalf("got name %q", name)
+	}
+	got := make([]byte, size)
+	if _, err := io.ReadFull(ole.OpenFile(files[1], files[0]), got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data[:size]) {
+		t.Fatal("stream content mismatch")
+	}
+}
@@ -0,0 +1,330 @@
+package ole2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// Well known property set format identifiers.
+var (
+	FMTIDSummaryInformation    = GUID{0xE0, 0x85, 0x9F, 0xF2, 0xF9, 0x4F, 0x68, 0x10, 0xAB, 0x91, 0x08, 0x00, 0x2B, 0x27, 0xB3, 0xD9}
+	FMTIDDocSummaryInformation = GUID{0x02, 0xD5, 0xCD, 0xD5, 0x9C, 0x2E, 0x1B, 0x10, 0x93, 0x97, 0x08, 0x00, 0x2B, 0x2C, 0xF9, 0xAE}
+	FMTIDUserDefinedProperties = GUID{0x05, 0xD5, 0xCD, 0xD5, 0x9C, 0x2E, 0x1B, 0x10, 0x93, 0x97, 0x08, 0x00, 0x2B, 0x2C, 0xF9, 0xAE}
+)
+
+// Property types.
+const (
+	VT_EMPTY    = 0
+	VT_I2       = 2
+	VT_I4       = 3
+	VT_R4       = 4
+	VT_R8       = 5
+	VT_BOOL     = 11
+	VT_VARIANT  = 12
+	VT_I1       = 16
+	VT_UI1      = 17
+	VT_UI2      = 18
+	VT_UI4      = 19
+	VT_I8       = 20
+	VT_UI8      = 21
+	VT_LPSTR    = 30
+	VT_LPWSTR   = 31
+	VT_FILETIME = 64
+	VT_BLOB     = 65
+	VT_CF       = 71
+	VT_VECTOR   = 0x1000
+)
+
+// PropertySet is one section of a property set stream.
+type PropertySet struct {
+	FMTID    GUID
+	CodePage uint16
+	// Properties by identifier. Values are string, int32, uint32, int64,
+	// uint64, float64, bool, time.Time, []byte or []interface{} for vectors.
+	Properties map[uint32]interface{}
+	// Names of properties from the dictionary, used by custom properties.
+	Names map[uint32]string
+}
+
+// ReadPropertySets parses a property set stream such as "\x05SummaryInformation".
+func ReadPropertySets(r io.Reader) ([]*PropertySet, error) {
+	bts, err := ioutil.ReadAll(r)
+	if err != nil && len(bts) == 0 {
+		return nil, err
+	}
+	if len(bts) < 28 || binary.LittleEndian.Uint16(bts) != 0xFFFE {
+		return nil, fmt.Errorf("not a property set stream")
+	}
+	count := binary.LittleEndian.Uint32(bts[24:])
+	if count > 16 || 28+int(count)*20 > len(bts) {
+		return nil, fmt.Errorf("invalid property set count %d", count)
+	}
+	var sets []*PropertySet
+	for i := 0; i < int(count); i++ {
+		p := bts[28+i*20:]
+		ps := &PropertySet{
+			Properties: make(map[uint32]interface{}),
+		}
+		copy(ps.FMTID[:], p[:16])
+		offset := binary.LittleEndian.Uint32(p[16:])
+		if uint64(offset)+8 > uint64(len(bts)) {
+			return nil, fmt.Errorf("property set offset %d out of range", offset)
+		}
+		if err := ps.parse(bts[offset:]); err != nil {
+			return nil, err
+		}
+		sets = append(sets, ps)
+	}
+	return sets, nil
+}
+
+func (ps *PropertySet) parse(sec []byte) error {
+	size := binary.LittleEndian.Uint32(sec)
+	if size < 8 {
+		return fmt.Errorf("invalid property set size %d", size)
+	}
+	if uint64(size) < uint64(len(sec)) {
+		sec = sec[:size]
+	}
+	count := binary.LittleEndian.Uint32(sec[4:])
+	if 8+uint64(count)*8 > uint64(len(sec)) {
+		return fmt.Errorf("invalid property count %d", count)
+	}
+	type entry struct{ id, offset uint32 }
+	entries := make([]entry, count)
+	for i := range entries {
+		entries[i].id = binary.LittleEndian.Uint32(sec[8+i*8:])
+		entries[i].offset = binary.LittleEndian.Uint32(sec[12+i*8:])
+	}
+	// The code page is needed to decode strings, read it first.
+	for _, e := range entries {
+		if e.id == 1 && uint64(e.offset)+6 <= uint64(len(sec)) {
+			ps.CodePage = binary.LittleEndian.Uint16(sec[e.offset+4:])
+		}
+	}
+	for _, e := range entries {
+		if uint64(e.offset)+4 > uint64(len(sec)) {
+			continue
+		}
+		r := bytes.NewReader(sec[e.offset:])
+		if e.id == 0 {
+			ps.Names = ps.readDictionary(r)
+			continue
+		}
+		var typ uint32
+		binary.Read(r, binary.LittleEndian, &typ)
+		v, err := ps.readValue(r, uint16(typ))
+		if err != nil {
+			continue
+		}
+		ps.Properties[e.id] = v
+	}
+	return nil
+}
+
+func (ps *PropertySet) readDictionary(r *bytes.Reader) map[uint32]string {
+	names := make(map[uint32]string)
+	var count uint32
+	binary.Read(r, binary.LittleEndian, &count)
+	for i := uint32(0); i < count && r.Len() > 0; i++ {
+		var id, length uint32
+		binary.Read(r, binary.LittleEndian, &id)
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil || length > uint32(r.Len()) {
+			break
+		}
+		if ps.CodePage == 1200 {
+			u := make([]uint16, length)
+			binary.Read(r, binary.LittleEndian, u)
+			if pad := (length * 2) % 4; pad != 0 {
+				r.Seek(int64(4-pad), io.SeekCurrent)
+			}
+			names[id] = trimNull(string(utf16.Decode(u)))
+			continue
+		}
+		b := make([]byte, length)
+		r.Read(b)
+		names[id] = DecodeString(b, ps.CodePage)
+	}
+	return names
+}
+
+func (ps *PropertySet) readValue(r *bytes.Reader, typ uint16) (interface{}, error) {
+	if typ&VT_VECTOR != 0 {
+		var count uint32
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return nil, err
+		}
+		if int(count) > r.Len() {
+			return nil, fmt.Errorf("vector count %d too large", count)
+		}
+		vals := make([]interface{}, 0, count)
+		for i := uint32(0); i < count; i++ {
+			et := typ &^ VT_VECTOR
+			if et == VT_VARIANT {
+				var vt uint32
+				if err := binary.Read(r, binary.LittleEndian, &vt); err != nil {
+					return nil, err
+				}
+				et = uint16(vt)
+			}
+			v, err := ps.readValue(r, et)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+		return vals, nil
+	}
+	le := binary.LittleEndian
+	var err error
+	read := func(n int) []byte {
+		b := make([]byte, n)
+		if _, e := io.ReadFull(r, b); e != nil {
+			err = e
+		}
+		return b
+	}
+	// Values are padded to a multiple of four bytes.
+	align := func(n int) {
+		if pad := n % 4; pad != 0 {
+			r.Seek(int64(4-pad), io.SeekCurrent)
+		}
+	}
+	var v interface{}
+	switch typ {
+	case VT_EMPTY:
+	case VT_I1:
+		v = int32(int8(read(4)[0]))
+	case VT_UI1:
+		v = uint32(read(4)[0])
+	case VT_I2:
+		v = int32(int16(le.Uint16(read(4))))
+	case VT_UI2:
+		v = uint32(le.Uint16(read(4)))
+	case VT_I4:
+		v = int32(le.Uint32(read(4)))
+	case VT_UI4:
+		v = le.Uint32(read(4))
+	case VT_I8:
+		v = int64(le.Uint64(read(8)))
+	case VT_UI8:
+		v = le.Uint64(read(8))
+	case VT_R4:
+		v = float64(math.Float32frombits(le.Uint32(read(4))))
+	case VT_R8:
+		v = math.Float64frombits(le.Uint64(read(8)))
+	case VT_BOOL:
+		v = le.Uint16(read(4)) != 0
+	case VT_FILETIME:
+		v = filetime(le.Uint64(read(8)))
+	case VT_LPSTR:
+		n := le.Uint32(read(4))
+		if int(n) > r.Len() {
+			return nil, fmt.Errorf("string size %d too large", n)
+		}
+		b := read(int(n))
+		if ps.CodePage == 1200 {
+			u := make([]uint16, len(b)/2)
+			for i := range u {
+				u[i] = le.Uint16(b[2*i:])
+			}
+			v = trimNull(string(utf16.Decode(u)))
+		} else {
+			v = DecodeString(b, ps.CodePage)
+		}
+		align(int(n))
+	case VT_LPWSTR:
+		n := le.Uint32(read(4))
+		if int(n)*2 > r.Len() {
+			return nil, fmt.Errorf("string size %d too large", n)
+		}
+		b := read(int(n) * 2)
+		u := make([]uint16, n)
+		for i := range u {
+			u[i] = le.Uint16(b[2*i:])
+		}
+		v = trimNull(string(utf16.Decode(u)))
+		align(int(n) * 2)
+	case VT_BLOB, VT_CF:
+		n := le.Uint32(read(4))
+		if int(n) > r.Len() {
+			return nil, fmt.Errorf("blob size %d too large", n)
+		}
+		v = read(int(n))
+		align(int(n))
+	default:
+		return nil, fmt.Errorf("unsupported property type 0x%X", typ)
+	}
+	return v, err
+}
+
+func trimNull(s string) string {
+	for i, c := range s {
+		if c == 0 {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+var codepages = map[uint16]encoding.Encoding{
+	437:   charmap.CodePage437,
+	850:   charmap.CodePage850,
+	852:   charmap.CodePage852,
+	866:   charmap.CodePage866,
+	874:   charmap.Windows874,
+	932:   japanese.ShiftJIS,
+	936:   simplifiedchinese.GBK,
+	949:   korean.EUCKR,
+	950:   traditionalchinese.Big5,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	1253:  charmap.Windows1253,
+	1254:  charmap.Windows1254,
+	1255:  charmap.Windows1255,
+	1256:  charmap.Windows1256,
+	1257:  charmap.Windows1257,
+	1258:  charmap.Windows1258,
+	10000: charmap.Macintosh,
+	10001: japanese.ShiftJIS,
+	10002: traditionalchinese.Big5,
+	10003: korean.EUCKR,
+	10008: simplifiedchinese.GBK,
+	20932: japanese.EUCJP,
+	28591: charmap.ISO8859_1,
+	51949: korean.EUCKR,
+	54936: simplifiedchinese.GB18030,
+}
+
+// DecodeString decodes a null terminated byte string in a Windows code page.
+// Unknown code pages are decoded as Windows-1252.
+func DecodeString(b []byte, codepage uint16) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	if codepage == 65001 {
+		return string(b)
+	}
+	enc, ok := codepages[codepage]
+	if !ok {
+		enc = charmap.Windows1252
+	}
+	out, err := enc.NewDecoder().Bytes(b)
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
@@ -0,0 +1,156 @@
+package ole2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+type testProp struct {
+	id  uint32
+	val []byte
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func pad4(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func utf16le(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s + "\x00")) {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	return b
+}
+
+func buildSection(props []testProp) []byte {
+	head := 8 + len(props)*8
+	var body []byte
+	var index []byte
+	for _, p := range props {
+		index = append(index, u32(p.id)...)
+		index = append(index, u32(uint32(head+len(body)))...)
+		body = append(body, pad4(p.val)...)
+	}
+	sec := append(u32(uint32(head+len(body))), u32(uint32(len(props)))...)
+	sec = append(sec, index...)
+	return append(sec, body...)
+}
+
+func buildPropertyStream(fmtids []GUID, sections [][]byte) []byte {
+	b := []byte{0xFE, 0xFF, 0, 0, 0x06, 0x0A, 0x02, 0}
+	b = append(b, make([]byte, 16)...)
+	b = append(b, u32(uint32(len(sections)))...)
+	offset := len(b) + 20*len(sections)
+	for i, s := range sections {
+		b = append(b, fmtids[i][:]...)
+		b = append(b, u32(uint32(offset))...)
+		offset += len(s)
+	}
+	for _, s := range sections {
+		b = append(b, s...)
+	}
+	return b
+}
+
+func TestReadPropertySets(t *testing.T) {
+	created := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	ft := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ft, filetimeOf(created))
+
+	vec := append(u32(VT_VECTOR|VT_VARIANT), u32(2)...)
+	vec = append(vec, u32(VT_LPSTR)...)
+	vec = append(vec, u32(3)...)
+	vec = append(vec, pad4([]byte("ab\x00"))...)
+	vec = append(vec, u32(VT_I4)...)
+	vec = append(vec, u32(3)...)
+	doc := buildSection([]testProp{
+		{1, append(u32(VT_I2), 0xE4, 0x04)},
+		{15, append(append(u32(VT_LPSTR), u32(5)...), "Caf\xE9\x00"...)},
+		{12, vec},
+	})
+
+	dict := u32(2)
+	dict = append(dict, u32(2)...)
+	dict = append(dict, u32(7)...)
+	dict = append(dict, pad4(utf16le("Client"))...)
+	dict = append(dict, u32(3)...)
+	dict = append(dict, u32(5)...)
+	dict = append(dict, pad4(utf16le("Date"))...)
+	custom := buildSection([]testProp{
+		{0, dict},
+		{1, append(u32(VT_I2), 0xB0, 0x04)},
+		{2, append(append(u32(VT_LPWSTR), u32(5)...), utf16le("Иван")...)},
+		{3, append(u32(VT_FILETIME), ft...)},
+	})
+
+	stream := buildPropertyStream(
+		[]GUID{FMTIDDocSummaryInformation, FMTIDUserDefinedProperties},
+		[][]byte{doc, custom},
+	)
+	sets, err := ReadPropertySets(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("got %d sets", len(sets))
+	}
+	if sets[0].FMTID != FMTIDDocSummaryInformation || sets[0].CodePage != 1252 {
+		t.Fatalf("got %v code page %d", sets[0].FMTID, sets[0].CodePage)
+	}
+	if got := sets[0].Properties[15]; got != "Café" {
+		t.Fatalf("got company %q", got)
+	}
+	got, ok := sets[0].Properties[12].([]interface{})
+	if !ok || len(got) != 2 || got[0] != "ab" || got[1] != int32(3) {
+		t.Fatalf("got vector %#v", sets[0].Properties[12])
+	}
+
+	c := sets[1]
+	if c.CodePage != 1200 || c.Names[2] != "Client" || c.Names[3] != "Date" {
+		t.Fatalf("got code page %d names %q", c.CodePage, c.Names)
+	}
+	if got := c.Properties[2]; got != "Иван" {
+		t.Fatalf("got %q", got)
+	}
+	if got, _ := c.Properties[3].(time.Time); !got.Equal(created) {
+		t.Fatalf("got time %v", c.Properties[3])
+	}
+}
+
+func TestReadPropertySetsTruncated(t *testing.T) {
+	sec := buildSection([]testProp{{1, append(u32(VT_I2), 0xE4, 0x04)}})
+	binary.LittleEndian.PutUint32(sec, 4)
+	stream := buildPropertyStream([]GUID{FMTIDSummaryInformation}, [][]byte{sec})
+	if _, err := ReadPropertySets(bytes.NewReader(stream)); err == nil {
+		t.Fatal("expected error for a truncated section")
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		cp   uint16
+		want string
+	}{
+		{"Caf\xE9\x00junk", 1252, "Café"},
+		{"\xCF\xF0\xE8\xE2\xE5\xF2", 1251, "Привет"},
+		{"\xD3\xC3\xBB\xA7", 10008, "用户"},
+		{"plain", 0, "plain"},
+	} {
+		if got := DecodeString([]byte(tc.in), tc.cp); got != tc.want {
+			t.Errorf("code page %d: got %q, want %q", tc.cp, got, tc.want)
+		}
+	}
+}
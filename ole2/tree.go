@@ -0,0 +1,201 @@
+package ole2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Entry is a storage or stream in the directory tree.
+type Entry struct {
+	*File
+	ID       uint32 // Index in the directory stream.
+	Path     string // Names from the root separated by "/", empty for the root.
+	Parent   *Entry
+	Children []*Entry // Sorted in directory order.
+}
+
+// IsStorage reports if the entry may hold children.
+func (e *Entry) IsStorage() bool {
+	return e.Type == USERSTORAGE || e.Type == ROOT
+}
+
+// IsStream reports if the entry holds data.
+func (e *Entry) IsStream() bool {
+	return e.Type == USERSTREAM
+}
+
+// Child returns the direct child with the given name or nil.
+// Names compare case-insensitive as they do in the directory.
+func (e *Entry) Child(name string) *Entry {
+	for _, c := range e.Children {
+		if strings.EqualFold(c.Name(), name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Lookup a descendant by a "/" separated path relative to e.
+func (e *Entry) Lookup(path string) *Entry {
+	cur := e
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		cur = cur.Child(name)
+		if cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+// Walk calls fn for e and every descendant, parents before children.
+// If fn returns an error the walk stops and returns it.
+func (e *Entry) Walk(fn func(*Entry) error) error {
+	if err := fn(e); err != nil {
+		return err
+	}
+	for _, c := range e.Children {
+		if err := c.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir reads every directory entry, keeping the entry index.
+func (o *Ole) readDir() ([]File, error) {
+	sector := o.stream_read(o.header.Dirstart, 0)
+	var dir []File
+	for {
+		var d File
+		err := binary.Read(sector, binary.LittleEndian, &d)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dir = append(dir, d)
+	}
+	if len(dir) == 0 || dir[0].Type != ROOT {
+		return nil, fmt.Errorf("missing root directory entry")
+	}
+	return dir, nil
+}
+
+// Root reads the directory and returns it as a tree of entries.
+func (o *Ole) Root() (*Entry, error) {
+	dir, err := o.readDir()
+	if err != nil {
+		return nil, err
+	}
+	seen := make([]bool, len(dir))
+	root := &Entry{File: &dir[0]}
+	seen[0] = true
+
+	var siblings func(parent *Entry, id uint32) error
+	siblings = func(parent *Entry, id uint32) error {
+		if id == NOSTREAM {
+			return nil
+		}
+		if id >= uint32(len(dir)) {
+			return fmt.Errorf("directory entry %d out of range", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("directory entry %d linked twice", id)
+		}
+		seen[id] = true
+		f := &dir[id]
+		if err := siblings(parent, f.Left); err != nil {
+			return err
+		}
+		e := &Entry{File: f, ID: id, Parent: parent}
+		e.Path = e.Name()
+		if parent.Path != "" {
+			e.Path = parent.Path + "/" + e.Path
+		}
+		parent.Children = append(parent.Children, e)
+		if e.IsStorage() {
+			if err := siblings(e, f.Child); err != nil {
+				return err
+			}
+		}
+		return siblings(parent, f.Right)
+	}
+	if err := siblings(root, root.File.Child); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Lookup an entry by a "/" separated path from the root, such as
+// "_VBA_PROJECT_CUR/VBA/Module1".
+func (o *Ole) Lookup(path string) (*Entry, error) {
+	root, err := o.Root()
+	if err != nil {
+		return nil, err
+	}
+	e := root.Lookup(path)
+	if e == nil {
+		return nil, fmt.Errorf("%q not found", path)
+	}
+	return e, nil
+}
+
+// FindCLSID returns all storages with the given CLSID.
+func (o *Ole) FindCLSID(clsid GUID) ([]*Entry, error) {
+	root, err := o.Root()
+	if err != nil {
+		return nil, err
+	}
+	var found []*Entry
+	root.Walk(func(e *Entry) error {
+		if e.IsStorage() && e.CLSID() == clsid {
+			found = append(found, e)
+		}
+		return nil
+	})
+	return found, nil
+}
+
+// OpenEntry opens a stream entry for reading.
+func (o *Ole) OpenEntry(e *Entry) (io.ReadSeeker, error) {
+	if !e.IsStream() {
+		return nil, fmt.Errorf("%q is not a stream", e.Path)
+	}
+	root := e
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return o.OpenFile(e.File, root.File), nil
+}
+
+// ReadEntry reads a stream entry without the padding of its last sector.
+func (o *Ole) ReadEntry(e *Entry) ([]byte, error) {
+	r, err := o.OpenEntry(e)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > int64(e.Size) {
+		b = b[:e.Size]
+	}
+	return b, nil
+}
+
+// OpenStream opens the stream at path for reading.
+func (o *Ole) OpenStream(path string) (io.ReadSeeker, error) {
+	e, err := o.Lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return o.OpenEntry(e)
+}
@@ -0,0 +1,82 @@
+package ole2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "table.xls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ole, err := Open(f, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := ole.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	root.Walk(func(e *Entry) error {
+		got = append(got, e.Path)
+		return nil
+	})
+	want := []string{"", "\x01CompObj", "Workbook", "\x05SummaryInformation", "\x05DocumentSummaryInformation"}
+	if len(got) != len(want) {
+		t.Fatalf("got paths %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got paths %q, want %q", got, want)
+		}
+	}
+	if root.Modified().Year() != 2015 {
+		t.Fatalf("unexpected root modified time %v", root.Modified())
+	}
+
+	r, err := ole.OpenStream("/workbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bts, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bts) < 16835 || bts[0] != 0x09 || bts[1] != 0x08 {
+		t.Fatalf("unexpected workbook stream start % X", bts[:4])
+	}
+	if _, err := ole.Lookup("Workbook/Missing"); err == nil {
+		t.Fatal("expected lookup error")
+	}
+}
+
+func TestReadEntry(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "table.xls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ole, err := Open(f, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ole.Lookup("\x01CompObj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ole.ReadEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != int(e.Size) {
+		t.Fatalf("got %d bytes, want %d", len(b), e.Size)
+	}
+	if _, err := ole.ReadEntry(e.Parent); err == nil {
+		t.Fatal("expected error reading a storage")
+	}
+}
@@ -0,0 +1,428 @@
+package ole2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	fatSect  = uint32(0xFFFFFFFD)
+	difSect  = uint32(0xFFFFFFFC)
+	miniSize = 64
+	cutoff   = 4096
+
+	colorRed   = 0
+	colorBlack = 1
+)
+
+// Storage is a storage in a compound file being written.
+type Storage struct {
+	Name     string
+	CLSID    GUID
+	Created  time.Time
+	Modified time.Time
+
+	storages []*Storage
+	streams  []*wstream
+}
+
+type wstream struct {
+	name string
+	data []byte
+}
+
+// AddStorage adds a child storage and returns it.
+func (s *Storage) AddStorage(name string) *Storage {
+	c := &Storage{Name: name}
+	s.storages = append(s.storages, c)
+	return c
+}
+
+// AddStream adds a child stream holding data.
+func (s *Storage) AddStream(name string, data []byte) {
+	s.streams = append(s.streams, &wstream{name: name, data: data})
+}
+
+// Writer builds a compound file from a tree of storages and streams.
+type Writer struct {
+	// SectorSize is 512 for version 3 files or 4096 for version 4 files.
+	SectorSize uint32
+	// Root is the root storage, its name is always written as "Root Entry".
+	Root *Storage
+}
+
+// NewWriter returns a writer for version 3 files with 512 byte sectors.
+func NewWriter() *Writer {
+	return &Writer{
+		SectorSize: 512,
+		Root:       &Storage{Name: "Root Entry"},
+	}
+}
+
+// node is a directory entry being laid out.
+type node struct {
+	File
+	name     string
+	data     []byte
+	storage  *Storage
+	children []*node
+	depth    int
+}
+
+func compareNames(a, b string) int {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	if len(ua) != len(ub) {
+		if len(ua) < len(ub) {
+			return -1
+		}
+		return 1
+	}
+	for i := range ua {
+		ca, cb := unicode.ToUpper(rune(ua[i])), unicode.ToUpper(rune(ub[i]))
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func filetimeOf(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	const epochDiff = 116444736000000000
+	return uint64(t.UnixNano()/100) + epochDiff
+}
+
+func newNode(name string, typ byte) (*node, error) {
+	if strings.ContainsAny(name, "/\\:!") {
+		return nil, fmt.Errorf("invalid character in name %q", name)
+	}
+	u := utf16.Encode([]rune(name))
+	if len(u) == 0 || len(u) > 31 {
+		return nil, fmt.Errorf("name %q must be 1 to 31 characters", name)
+	}
+	n := &node{name: name}
+	copy(n.NameBts[:], u)
+	n.Bsize = uint16(len(u)+1) * 2
+	n.Type = typ
+	n.Left, n.Right, n.Child = NOSTREAM, NOSTREAM, NOSTREAM
+	n.Sstart = ENDOFCHAIN
+	return n, nil
+}
+
+func (n *node) setCLSID(g GUID) {
+	for i, v := range g {
+		n.Guid[i/2] |= uint16(v) << (8 * uint(i%2))
+	}
+}
+
+// flatten appends s and all descendants to list in directory order.
+func (w *Writer) flatten(s *Storage, n *node, list []*node) ([]*node, error) {
+	for _, c := range s.streams {
+		cn, err := newNode(c.name, USERSTREAM)
+		if err != nil {
+			return nil, err
+		}
+		cn.data = c.data
+		n.children = append(n.children, cn)
+	}
+	for _, c := range s.storages {
+		cn, err := newNode(c.Name, USERSTORAGE)
+		if err != nil {
+			return nil, err
+		}
+		cn.storage = c
+		cn.setCLSID(c.CLSID)
+		cn.Time = [2]uint64{filetimeOf(c.Created), filetimeOf(c.Modified)}
+		n.children = append(n.children, cn)
+	}
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return compareNames(n.children[i].name, n.children[j].name) < 0
+	})
+	for i := 1; i < len(n.children); i++ {
+		if compareNames(n.children[i-1].name, n.children[i].name) == 0 {
+			return nil, fmt.Errorf("duplicate name %q in %q", n.children[i].name, n.name)
+		}
+	}
+	first := uint32(len(list))
+	list = append(list, n.children...)
+	n.Child = balance(list, first, uint32(len(list)), 0)
+	colorTree(list[first:])
+
+	var err error
+	for _, c := range n.children {
+		if c.storage == nil {
+			continue
+		}
+		list, err = w.flatten(c.storage, c, list)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// balance links list[lo:hi] as a balanced binary tree and returns its root.
+func balance(list []*node, lo, hi uint32, depth int) uint32 {
+	if lo >= hi {
+		return NOSTREAM
+	}
+	mid := lo + (hi-lo)/2
+	n := list[mid]
+	n.depth = depth
+	n.Left = balance(list, lo, mid, depth+1)
+	n.Right = balance(list, mid+1, hi, depth+1)
+	return mid
+}
+
+// colorTree colors a balanced tree so it is a valid red-black tree: all
+// levels but the last are full, so only the deepest level is red.
+func colorTree(list []*node) {
+	max := 0
+	for _, n := range list {
+		if n.depth > max {
+			max = n.depth
+		}
+	}
+	for _, n := range list {
+		n.Flag = colorBlack
+		if max > 0 && n.depth == max {
+			n.Flag = colorRed
+		}
+	}
+}
+
+func sectors(size, ss uint32) uint32 {
+	return (size + ss - 1) / ss
+}
+
+// WriteTo writes the compound file to out.
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	ss := w.SectorSize
+	var shift, version uint16
+	switch ss {
+	case 512:
+		shift, version = 9, 3
+	case 4096:
+		shift, version = 12, 4
+	default:
+		return 0, fmt.Errorf("unsupported sector size %d", ss)
+	}
+
+	root, _ := newNode("Root Entry", ROOT)
+	root.Flag = colorBlack
+	if w.Root != nil {
+		root.Time[1] = filetimeOf(w.Root.Modified)
+		root.setCLSID(w.Root.CLSID)
+	}
+	list := []*node{root}
+	if w.Root != nil {
+		var err error
+		list, err = w.flatten(w.Root, root, list)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Small streams go into the mini stream held by the root entry.
+	var mini bytes.Buffer
+	var minifat []uint32
+	for _, n := range list {
+		if n.Type != USERSTREAM {
+			continue
+		}
+		if uint64(len(n.data)) > 0xFFFFFFFF {
+			return 0, fmt.Errorf("stream %q too large", n.name)
+		}
+		n.Size = uint32(len(n.data))
+		if n.Size == 0 || n.Size >= cutoff {
+			continue
+		}
+		start := uint32(len(minifat))
+		count := sectors(n.Size, miniSize)
+		for i := uint32(1); i < count; i++ {
+			minifat = append(minifat, start+i)
+		}
+		minifat = append(minifat, ENDOFCHAIN)
+		n.Sstart = start
+		mini.Write(n.data)
+		mini.Write(make([]byte, count*miniSize-n.Size))
+	}
+
+	dirSectors := sectors(uint32(len(list))*128, ss)
+	minifatSectors := sectors(uint32(len(minifat))*4, ss)
+	miniSectors := sectors(uint32(mini.Len()), ss)
+	content := dirSectors + minifatSectors + miniSectors
+	for _, n := range list {
+		if n.Type == USERSTREAM && n.Size >= cutoff {
+			content += sectors(n.Size, ss)
+		}
+	}
+
+	perSector := ss / 4
+	var fatSectors, difSectors uint32
+	for {
+		total := content + fatSectors + difSectors
+		needFat := sectors(total, perSector)
+		var needDif uint32
+		if needFat > 109 {
+			needDif = sectors(needFat-109, perSector-1)
+		}
+		if needFat == fatSectors && needDif == difSectors {
+			break
+		}
+		fatSectors, difSectors = needFat, needDif
+	}
+
+	fat := make([]uint32, fatSectors*perSector)
+	for i := range fat {
+		fat[i] = FREESECT
+	}
+	next := uint32(0)
+	chain := func(count uint32) uint32 {
+		if count == 0 {
+			return ENDOFCHAIN
+		}
+		start := next
+		for i := uint32(0); i < count-1; i++ {
+			fat[start+i] = start + i + 1
+		}
+		fat[start+count-1] = ENDOFCHAIN
+		next += count
+		return start
+	}
+	for i := uint32(0); i < fatSectors; i++ {
+		fat[next] = fatSect
+		next++
+	}
+	difStart := ENDOFCHAIN
+	if difSectors > 0 {
+		difStart = next
+	}
+	for i := uint32(0); i < difSectors; i++ {
+		fat[next] = difSect
+		next++
+	}
+	dirStart := chain(dirSectors)
+	minifatStart := chain(minifatSectors)
+	root.Sstart = chain(miniSectors)
+	root.Size = uint32(mini.Len())
+	for _, n := range list {
+		if n.Type == USERSTREAM && n.Size >= cutoff {
+			n.Sstart = chain(sectors(n.Size, ss))
+		}
+	}
+
+	header := &Header{
+		ID:           [2]uint32{0xE011CFD0, 0xE11AB1A1},
+		Verminor:     0x3E,
+		Verdll:       version,
+		ByteOrder:    0xFFFE,
+		Lsectorb:     shift,
+		Lssectorb:    6,
+		Cfat:         fatSectors,
+		Dirstart:     dirStart,
+		Sectorcutoff: cutoff,
+		Sfatstart:    ENDOFCHAIN,
+		Csfat:        minifatSectors,
+		Difstart:     difStart,
+		Cdif:         difSectors,
+	}
+	if minifatSectors > 0 {
+		header.Sfatstart = minifatStart
+	}
+	for i := range header.Msat {
+		header.Msat[i] = FREESECT
+		if uint32(i) < fatSectors {
+			header.Msat[i] = uint32(i)
+		}
+	}
+	var hbuf bytes.Buffer
+	binary.Write(&hbuf, binary.LittleEndian, header)
+	hbts := hbuf.Bytes()
+	if version == 4 {
+		// Number of directory sectors, always zero for version 3.
+		binary.LittleEndian.PutUint32(hbts[0x28:], dirSectors)
+	}
+
+	cw := &countWriter{w: out}
+	cw.Write(hbts)
+	cw.pad(ss)
+
+	binary.Write(cw, binary.LittleEndian, fat)
+
+	// DIFAT sectors list the FAT sectors beyond the first 109.
+	for i := uint32(0); i < difSectors; i++ {
+		ids := make([]uint32, perSector)
+		for j := uint32(0); j < perSector-1; j++ {
+			ids[j] = FREESECT
+			if k := 109 + i*(perSector-1) + j; k < fatSectors {
+				ids[j] = k
+			}
+		}
+		ids[perSector-1] = ENDOFCHAIN
+		if i+1 < difSectors {
+			ids[perSector-1] = difStart + i + 1
+		}
+		binary.Write(cw, binary.LittleEndian, ids)
+	}
+
+	for _, n := range list {
+		binary.Write(cw, binary.LittleEndian, &n.File)
+	}
+	empty := File{Left: NOSTREAM, Right: NOSTREAM, Child: NOSTREAM}
+	for i := uint32(len(list)); i < dirSectors*ss/128; i++ {
+		binary.Write(cw, binary.LittleEndian, &empty)
+	}
+
+	if minifatSectors > 0 {
+		for uint32(len(minifat))%perSector != 0 {
+			minifat = append(minifat, FREESECT)
+		}
+		binary.Write(cw, binary.LittleEndian, minifat)
+	}
+	cw.Write(mini.Bytes())
+	cw.pad(ss)
+	for _, n := range list {
+		if n.Type == USERSTREAM && n.Size >= cutoff {
+			cw.Write(n.data)
+			cw.pad(ss)
+		}
+	}
+	return cw.n, cw.err
+}
+
+// countWriter counts bytes written and keeps the first error.
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// pad writes zeros up to the next multiple of size.
+func (c *countWriter) pad(size uint32) {
+	if rem := c.n % int64(size); rem != 0 {
+		c.Write(make([]byte, int64(size)-rem))
+	}
+}
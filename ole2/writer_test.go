@@ -0,0 +1,181 @@
+package ole2
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func pattern(n, seed int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*7 + seed)
+	}
+	return b
+}
+
+// checkRedBlack verifies the sibling tree of a storage is ordered and
+// a valid red-black tree, returning its black height.
+func checkRedBlack(t *testing.T, dir []File, id uint32, parentRed bool) int {
+	if id == NOSTREAM {
+		return 1
+	}
+	f := &dir[id]
+	red := f.Flag == colorRed
+	if red && parentRed {
+		t.Fatalf("red entry %q has a red parent", f.Name())
+	}
+	if f.Left != NOSTREAM && compareNames(dir[f.Left].Name(), f.Name()) >= 0 {
+		t.Fatalf("entry %q not ordered after %q", f.Name(), dir[f.Left].Name())
+	}
+	if f.Right != NOSTREAM && compareNames(dir[f.Right].Name(), f.Name()) <= 0 {
+		t.Fatalf("entry %q not ordered before %q", f.Name(), dir[f.Right].Name())
+	}
+	l := checkRedBlack(t, dir, f.Left, red)
+	r := checkRedBlack(t, dir, f.Right, red)
+	if l != r {
+		t.Fatalf("black height differs below %q: %d != %d", f.Name(), l, r)
+	}
+	if !red {
+		l++
+	}
+	return l
+}
+
+func TestWriter(t *testing.T) {
+	for _, ss := range []uint32{512, 4096} {
+		t.Run(fmt.Sprint(ss), func(t *testing.T) {
+			created := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+			clsid := GUID{0x20, 0x08, 0x02, 0, 0, 0, 0, 0, 0xC0, 0, 0, 0, 0, 0, 0, 0x46}
+
+			w := NewWriter()
+			w.SectorSize = ss
+			w.Root.CLSID = clsid
+			w.Root.AddStream("Workbook", pattern(10000, 1))
+			w.Root.AddStream("\x05SummaryInformation", pattern(300, 2))
+			w.Root.AddStream("Empty", nil)
+			vba := w.Root.AddStorage("_VBA_PROJECT_CUR")
+			vba.Created = created
+			vba.Modified = created.Add(time.Hour)
+			vba.AddStream("PROJECT", pattern(500, 3))
+			sub := vba.AddStorage("VBA")
+			sub.AddStream("dir", pattern(700, 4))
+			for i := 1; i <= 12; i++ {
+				sub.AddStream(fmt.Sprintf("Module%d", i), pattern(i*400, i))
+			}
+
+			buf := &bytes.Buffer{}
+			n, err := w.WriteTo(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) || n%int64(ss) != 0 {
+				t.Fatalf("wrote %d bytes, buffer has %d", n, buf.Len())
+			}
+
+			ole, err := Open(bytes.NewReader(buf.Bytes()), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ole.Lsector != ss {
+				t.Fatalf("got sector size %d", ole.Lsector)
+			}
+			root, err := ole.Root()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root.CLSID() != clsid {
+				t.Fatalf("got root CLSID %v", root.CLSID())
+			}
+
+			dir, err := ole.readDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.Walk(func(e *Entry) error {
+				if e.IsStorage() {
+					checkRedBlack(t, dir, e.File.Child, false)
+				}
+				return nil
+			})
+
+			check := func(path string, want []byte) {
+				r, err := ole.OpenStream(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) < len(want) || !bytes.Equal(got[:len(want)], want) {
+					t.Fatalf("%s: content mismatch", path)
+				}
+			}
+			check("Workbook", pattern(10000, 1))
+			check("\x05SummaryInformation", pattern(300, 2))
+			check("_VBA_PROJECT_CUR/PROJECT", pattern(500, 3))
+			check("_VBA_PROJECT_CUR/VBA/dir", pattern(700, 4))
+			for i := 1; i <= 12; i++ {
+				check(fmt.Sprintf("_VBA_PROJECT_CUR/VBA/Module%d", i), pattern(i*400, i))
+			}
+
+			e, err := ole.Lookup("_vba_project_cur")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !e.Created().Equal(created) || !e.Modified().Equal(created.Add(time.Hour)) {
+				t.Fatalf("got times %v %v", e.Created(), e.Modified())
+			}
+			if e := root.Child("Empty"); e == nil || e.Size != 0 {
+				t.Fatal("missing empty stream")
+			}
+		})
+	}
+}
+
+// TestWriterDIFAT writes a file large enough to need DIFAT sectors.
+func TestWriterDIFAT(t *testing.T) {
+	data := pattern(110*128*512, 5)
+	w := NewWriter()
+	w.Root.AddStream("Big", data)
+	w.Root.AddStream("Small", pattern(100, 6))
+	buf := &bytes.Buffer{}
+	if _, err := w.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	ole, err := Open(bytes.NewReader(buf.Bytes()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ole.header.Cdif == 0 {
+		t.Fatal("expected DIFAT sectors")
+	}
+	r, err := ole.OpenStream("Big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got[:len(data)], data) {
+		t.Fatal("content mismatch")
+	}
+}
+
+func TestWriterNames(t *testing.T) {
+	w := NewWriter()
+	w.Root.AddStream("Same", nil)
+	w.Root.AddStream("SAME", nil)
+	if _, err := w.WriteTo(ioutil.Discard); err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+	w = NewWriter()
+	w.Root.AddStream("a/b", nil)
+	if _, err := w.WriteTo(ioutil.Discard); err == nil {
+		t.Fatal("expected invalid name error")
+	}
+}
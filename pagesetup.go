@@ -0,0 +1,208 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// Orientation of printed pages.
+type Orientation byte
+
+const (
+	OrientationDefault Orientation = iota
+	OrientationPortrait
+	OrientationLandscape
+)
+
+// Common paper sizes, see the SETUP record for the full list.
+const (
+	PaperLetter = 1
+	PaperLegal  = 5
+	PaperA3     = 8
+	PaperA4     = 9
+	PaperA5     = 11
+)
+
+// HeaderFooter is a page header or footer. Left, Center and Right are the
+// sections of Text, still holding codes such as &P for the page number.
+type HeaderFooter struct {
+	Text   string
+	Left   string
+	Center string
+	Right  string
+}
+
+// PageBreak is a manual page break before row or column Index, limited to
+// the range from Start to End of the other dimension.
+type PageBreak struct {
+	Index uint16
+	Start uint16
+	End   uint16
+}
+
+// PageSetup holds the print settings of a worksheet.
+type PageSetup struct {
+	PaperSize   uint16
+	Orientation Orientation
+	// Scale is the print scaling in percent, used unless FitToPage is set.
+	Scale     int
+	FitToPage bool
+	// FitWidth and FitHeight are the number of pages to fit the sheet to,
+	// 0 for no limit.
+	FitWidth  int
+	FitHeight int
+	// FirstPage is the number of the first page, 0 for automatic.
+	FirstPage     int
+	Copies        int
+	LeftToRight   bool // Print pages across before down.
+	BlackAndWhite bool
+	Draft         bool
+
+	// Margins in inches.
+	LeftMargin   float64
+	RightMargin  float64
+	TopMargin    float64
+	BottomMargin float64
+	HeaderMargin float64
+	FooterMargin float64
+	HCenter      bool
+	VCenter      bool
+
+	Header HeaderFooter
+	Footer HeaderFooter
+
+	PrintGridlines bool
+	PrintHeaders   bool
+
+	// RowBreaks and ColBreaks are the manual page breaks.
+	RowBreaks []PageBreak
+	ColBreaks []PageBreak
+}
+
+type setupInfo struct {
+	PaperSize uint16
+	Scale     uint16
+	PageStart uint16
+	FitWidth  uint16
+	FitHeight uint16
+	Flags     uint16
+	Res       uint16
+	VRes      uint16
+	Header    float64
+	Footer    float64
+	Copies    uint16
+}
+
+func defaultPageSetup() PageSetup {
+	return PageSetup{
+		Scale:        100,
+		FitWidth:     1,
+		FitHeight:    1,
+		Copies:       1,
+		LeftMargin:   0.75,
+		RightMargin:  0.75,
+		TopMargin:    1,
+		BottomMargin: 1,
+		HeaderMargin: 0.5,
+		FooterMargin: 0.5,
+	}
+}
+
+// parseSetup reads the SETUP record.
+func (p *PageSetup) parseSetup(buf io.Reader) {
+	var s setupInfo
+	if binary.Read(buf, binary.LittleEndian, &s) != nil {
+		return
+	}
+	p.FitWidth, p.FitHeight = int(s.FitWidth), int(s.FitHeight)
+	p.LeftToRight = s.Flags&0x1 != 0
+	p.BlackAndWhite = s.Flags&0x8 != 0
+	p.Draft = s.Flags&0x10 != 0
+	if s.Flags&0x80 != 0 {
+		p.FirstPage = int(int16(s.PageStart))
+	}
+	p.HeaderMargin, p.FooterMargin = s.Header, s.Footer
+	// The printer fields are undefined without printer settings.
+	if s.Flags&0x4 != 0 {
+		return
+	}
+	p.PaperSize = s.PaperSize
+	p.Scale = int(s.Scale)
+	p.Copies = int(s.Copies)
+	if s.Flags&0x40 == 0 {
+		p.Orientation = OrientationLandscape
+		if s.Flags&0x2 != 0 {
+			p.Orientation = OrientationPortrait
+		}
+	}
+}
+
+// parsePageBreaks reads a HORIZONTALPAGEBREAKS or VERTICALPAGEBREAKS
+// record. BIFF5 breaks span the whole sheet.
+func parsePageBreaks(buf io.Reader, biff5 bool) []PageBreak {
+	var count uint16
+	binary.Read(buf, binary.LittleEndian, &count)
+	var breaks []PageBreak
+	for i := uint16(0); i < count; i++ {
+		var b PageBreak
+		if biff5 {
+			if binary.Read(buf, binary.LittleEndian, &b.Index) != nil {
+				break
+			}
+			b.End = 0xFFFF
+		} else if binary.Read(buf, binary.LittleEndian, &b) != nil {
+			break
+		}
+		breaks = append(breaks, b)
+	}
+	return breaks
+}
+
+// parseHeaderFooter reads a HEADER or FOOTER record, empty when the sheet
+// has none.
+func (w *WorkSheet) parseHeaderFooter(bts []byte) HeaderFooter {
+	if len(bts) == 0 {
+		return HeaderFooter{}
+	}
+	var size uint16
+	buf := bytes.NewReader(bts)
+	if w.wb.Is5ver {
+		var n byte
+		binary.Read(buf, binary.LittleEndian, &n)
+		size = uint16(n)
+	} else {
+		binary.Read(buf, binary.LittleEndian, &size)
+	}
+	text, _ := w.wb.getString(buf, size)
+	return newHeaderFooter(text)
+}
+
+// newHeaderFooter splits text at its &L, &C and &R codes. Text before the
+// first section code is centered.
+func newHeaderFooter(text string) HeaderFooter {
+	h := HeaderFooter{Text: text}
+	var left, center, right strings.Builder
+	cur := &center
+	for i := 0; i < len(text); i++ {
+		if text[i] != '&' || i+1 == len(text) {
+			cur.WriteByte(text[i])
+			continue
+		}
+		switch text[i+1] {
+		case 'L', 'l':
+			cur = &left
+		case 'C', 'c':
+			cur = &center
+		case 'R', 'r':
+			cur = &right
+		default:
+			// Keep other codes, including && for an ampersand.
+			cur.WriteString(text[i : i+2])
+		}
+		i++
+	}
+	h.Left, h.Center, h.Right = left.String(), center.String(), right.String()
+	return h
+}
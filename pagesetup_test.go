@@ -0,0 +1,63 @@
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func le64f(v float64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	return b
+}
+
+func TestPageSetup(t *testing.T) {
+	s := sheetFromRecords(t, nil)
+	if p := s.PageSetup; p.Scale != 100 || p.LeftMargin != 0.75 || p.Orientation != OrientationDefault {
+		t.Errorf("default page setup = %+v", p)
+	}
+
+	setup := le16(PaperA4, 85, 3, 2, 0, 0x0080, 600, 600)
+	setup = append(setup, le64f(0.3)...)
+	setup = append(setup, le64f(0.4)...)
+	setup = append(setup, le16(2)...)
+	s = sheetFromRecords(t, nil,
+		biffRecord(0x014, mustXLString("&LLeft&CPage &P of &N&RA && B", 2)),
+		biffRecord(0x015, mustXLString("Plain", 2)),
+		biffRecord(0x083, le16(1)),
+		biffRecord(0x02B, le16(1)),
+		biffRecord(0x01B, le16(2, 10, 0, 255, 20, 0, 255)),
+		biffRecord(0x01A, le16(1, 5, 0, 65535)),
+		biffRecord(0x026, le64f(0.5)),
+		biffRecord(0x029, le64f(1.25)),
+		biffRecord(0x081, le16(0x05C1)),
+		biffRecord(0x0A1, setup),
+	)
+	p := s.PageSetup
+	if p.PaperSize != PaperA4 || p.Scale != 85 || p.Orientation != OrientationLandscape || p.Copies != 2 {
+		t.Errorf("printer settings = %+v", p)
+	}
+	if !p.FitToPage || p.FitWidth != 2 || p.FitHeight != 0 || p.FirstPage != 3 {
+		t.Errorf("fit = %+v", p)
+	}
+	if p.LeftMargin != 0.5 || p.RightMargin != 0.75 || p.BottomMargin != 1.25 || p.HeaderMargin != 0.3 || p.FooterMargin != 0.4 {
+		t.Errorf("margins = %+v", p)
+	}
+	if !p.HCenter || p.VCenter || !p.PrintGridlines || p.PrintHeaders {
+		t.Errorf("flags = %+v", p)
+	}
+	want := HeaderFooter{Text: "&LLeft&CPage &P of &N&RA && B", Left: "Left", Center: "Page &P of &N", Right: "A && B"}
+	if p.Header != want {
+		t.Errorf("header = %+v, want %+v", p.Header, want)
+	}
+	if p.Footer.Center != "Plain" || p.Footer.Left != "" {
+		t.Errorf("footer = %+v", p.Footer)
+	}
+	if len(p.RowBreaks) != 2 || p.RowBreaks[1] != (PageBreak{Index: 20, End: 255}) {
+		t.Errorf("row breaks = %+v", p.RowBreaks)
+	}
+	if len(p.ColBreaks) != 1 || p.ColBreaks[0] != (PageBreak{Index: 5, End: 65535}) {
+		t.Errorf("col breaks = %+v", p.ColBreaks)
+	}
+}
@@ -0,0 +1,71 @@
+package xls
+
+import "image/color"
+
+// System color indices.
+const (
+	ColorWindowText       = 0x40   // Default foreground, used for borders and patterns.
+	ColorWindow           = 0x41   // Default background.
+	ColorFace             = 0x43   // Dialog face.
+	ColorChartForeground  = 0x4D   // Default chart foreground.
+	ColorChartBackground  = 0x4E   // Default chart background.
+	ColorChartNeutralLine = 0x4F   // Chart neutral line.
+	ColorToolTipText      = 0x51   // Comment text.
+	ColorFontAutomatic    = 0x7FFF // Automatic font color.
+)
+
+// builtinColors are the fixed colors at indices 0 to 7.
+var builtinColors = [8]uint32{
+	0x000000, 0xFFFFFF, 0xFF0000, 0x00FF00, 0x0000FF, 0xFFFF00, 0xFF00FF, 0x00FFFF,
+}
+
+// defaultPalette are the colors at indices 8 to 63 without a PALETTE record.
+var defaultPalette = [56]uint32{
+	0x000000, 0xFFFFFF, 0xFF0000, 0x00FF00, 0x0000FF, 0xFFFF00, 0xFF00FF, 0x00FFFF,
+	0x800000, 0x008000, 0x000080, 0x808000, 0x800080, 0x008080, 0xC0C0C0, 0x808080,
+	0x9999FF, 0x993366, 0xFFFFCC, 0xCCFFFF, 0x660066, 0xFF8080, 0x0066CC, 0xCCCCFF,
+	0x000080, 0xFF00FF, 0xFFFF00, 0x00FFFF, 0x800080, 0x800000, 0x008080, 0x0000FF,
+	0x00CCFF, 0xCCFFFF, 0xCCFFCC, 0xFFFF99, 0x99CCFF, 0xFF99CC, 0xCC99FF, 0xFFCC99,
+	0x3366FF, 0x33CCCC, 0x99CC00, 0xFFCC00, 0xFF9900, 0xFF6600, 0x666699, 0x969696,
+	0x003366, 0x339966, 0x003300, 0x333300, 0x993300, 0x993366, 0x333399, 0x333333,
+}
+
+// systemColors resolves system color indices to the usual Windows colors.
+var systemColors = map[uint16]uint32{
+	ColorWindowText:       0x000000,
+	ColorWindow:           0xFFFFFF,
+	ColorFace:             0xC0C0C0,
+	ColorChartForeground:  0x000000,
+	ColorChartBackground:  0xFFFFFF,
+	ColorChartNeutralLine: 0x000000,
+	ColorToolTipText:      0x000000,
+	ColorFontAutomatic:    0x000000,
+}
+
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{R: byte(v >> 16), G: byte(v >> 8), B: byte(v), A: 0xFF}
+}
+
+// Color resolves a color index from a font, style or border. Indices 8 to
+// 63 use the workbook palette. Unknown indices resolve to black.
+func (w *WorkBook) Color(idx uint16) color.RGBA {
+	switch {
+	case idx < 8:
+		return rgb(builtinColors[idx])
+	case idx < 64:
+		if i := int(idx - 8); i < len(w.palette) {
+			return w.palette[i]
+		}
+		return rgb(defaultPalette[idx-8])
+	}
+	return rgb(systemColors[idx])
+}
+
+// Palette returns the colors at indices 8 to 63.
+func (w *WorkBook) Palette() []color.RGBA {
+	p := make([]color.RGBA, len(defaultPalette))
+	for i := range p {
+		p[i] = w.Color(uint16(i + 8))
+	}
+	return p
+}
@@ -0,0 +1,54 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+)
+
+func TestPalette(t *testing.T) {
+	w := NewWriter()
+	w.AddSheet("Sheet1").SetString(0, 0, "x", 0)
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wb, err := OpenReader(bytes.NewReader(packWorkbook(t, stream)), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, want := range map[uint16]color.RGBA{
+		2:                  {0xFF, 0, 0, 0xFF},
+		10:                 {0xFF, 0, 0, 0xFF},
+		22:                 {0xC0, 0xC0, 0xC0, 0xFF},
+		63:                 {0x33, 0x33, 0x33, 0xFF},
+		ColorWindow:        {0xFF, 0xFF, 0xFF, 0xFF},
+		ColorFontAutomatic: {0, 0, 0, 0xFF},
+	} {
+		if got := wb.Color(idx); got != want {
+			t.Errorf("default color %d: got %v, want %v", idx, got, want)
+		}
+	}
+
+	body := make([]byte, 2, 2+56*4)
+	binary.LittleEndian.PutUint16(body, 56)
+	for i := 0; i < 56; i++ {
+		body = append(body, byte(i), 0x10, 0x20, 0)
+	}
+	stream, _ = insertRecords(stream, biffRecord(0x92, body))
+	wb, err = OpenReader(bytes.NewReader(packWorkbook(t, stream)), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := wb.Color(10), (color.RGBA{2, 0x10, 0x20, 0xFF}); got != want {
+		t.Fatalf("custom color: got %v, want %v", got, want)
+	}
+	if got, want := wb.Color(2), (color.RGBA{0xFF, 0, 0, 0xFF}); got != want {
+		t.Fatalf("builtin color: got %v, want %v", got, want)
+	}
+	if p := wb.Palette(); len(p) != 56 || p[55] != (color.RGBA{55, 0x10, 0x20, 0xFF}) {
+		t.Fatalf("got palette %v", p)
+	}
+}
@@ -0,0 +1,106 @@
+package xls
+
+import (
+	"time"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+// Properties are the document properties stored in the summary
+// information streams.
+type Properties struct {
+	Title       string
+	Subject     string
+	Author      string
+	Keywords    string
+	Comments    string
+	LastSavedBy string
+	Application string
+	Created     time.Time
+	Modified    time.Time
+
+	Category string
+	Manager  string
+	Company  string
+
+	// Custom holds user defined properties by name.
+	Custom map[string]interface{}
+}
+
+// Summary information property identifiers.
+const (
+	pidTitle       = 2
+	pidSubject     = 3
+	pidAuthor      = 4
+	pidKeywords    = 5
+	pidComments    = 6
+	pidLastAuthor  = 8
+	pidCreated     = 12
+	pidLastSaved   = 13
+	pidApplication = 18
+
+	pidCategory = 2
+	pidManager  = 14
+	pidCompany  = 15
+)
+
+// read fills p from the property set streams under root.
+// Missing or malformed streams are ignored, they never prevent reading
+// the workbook itself.
+func (p *Properties) read(ole *ole2.Ole, root *ole2.Entry) {
+	for _, name := range []string{"\x05SummaryInformation", "\x05DocumentSummaryInformation"} {
+		e := root.Child(name)
+		if e == nil || !e.IsStream() {
+			continue
+		}
+		r, err := ole.OpenEntry(e)
+		if err != nil {
+			continue
+		}
+		sets, err := ole2.ReadPropertySets(r)
+		if err != nil {
+			continue
+		}
+		for _, ps := range sets {
+			p.readSet(ps)
+		}
+	}
+}
+
+func (p *Properties) readSet(ps *ole2.PropertySet) {
+	str := func(id uint32) string {
+		s, _ := ps.Properties[id].(string)
+		return s
+	}
+	tm := func(id uint32) time.Time {
+		t, _ := ps.Properties[id].(time.Time)
+		return t
+	}
+	switch ps.FMTID {
+	case ole2.FMTIDSummaryInformation:
+		p.Title = str(pidTitle)
+		p.Subject = str(pidSubject)
+		p.Author = str(pidAuthor)
+		p.Keywords = str(pidKeywords)
+		p.Comments = str(pidComments)
+		p.LastSavedBy = str(pidLastAuthor)
+		p.Application = str(pidApplication)
+		p.Created = tm(pidCreated)
+		p.Modified = tm(pidLastSaved)
+	case ole2.FMTIDDocSummaryInformation:
+		p.Category = str(pidCategory)
+		p.Manager = str(pidManager)
+		p.Company = str(pidCompany)
+	case ole2.FMTIDUserDefinedProperties:
+		for id, name := range ps.Names {
+			v, ok := ps.Properties[id]
+			if !ok {
+				continue
+			}
+			if p.Custom == nil {
+				p.Custom = make(map[string]interface{})
+			}
+			p.Custom[name] = v
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package xls
+
+import "encoding/binary"
+
+// ProtectionAllow holds the operations allowed on a protected sheet.
+type ProtectionAllow uint16
+
+const (
+	AllowEditObjects ProtectionAllow = 1 << iota
+	AllowEditScenarios
+	AllowFormatCells
+	AllowFormatColumns
+	AllowFormatRows
+	AllowInsertColumns
+	AllowInsertRows
+	AllowInsertHyperlinks
+	AllowDeleteColumns
+	AllowDeleteRows
+	AllowSelectLockedCells
+	AllowSort
+	AllowAutoFilter
+	AllowPivotTables
+	AllowSelectUnlockedCells
+)
+
+// SheetProtection is the protection of a sheet.
+type SheetProtection struct {
+	Protected bool
+	// Objects and Scenarios are set when they are protected as well.
+	Objects   bool
+	Scenarios bool
+	// Password is the verifier of the password, 0 without password.
+	Password uint16
+	// Allow holds the operations allowed while the sheet is protected.
+	// Without enhanced protection, only selecting cells is allowed.
+	Allow ProtectionAllow
+}
+
+// CheckPassword reports whether password unprotects the sheet.
+func (p SheetProtection) CheckPassword(password string) bool {
+	return checkPassword(p.Password, password)
+}
+
+// WorkBookProtection is the protection of the workbook.
+type WorkBookProtection struct {
+	// Structure is set when sheets may not be added, removed, moved or
+	// renamed.
+	Structure bool
+	// Windows is set when the workbook windows may not be moved or
+	// resized.
+	Windows bool
+	// Password is the verifier of the password, 0 without password.
+	Password uint16
+}
+
+// CheckPassword reports whether password unprotects the workbook.
+func (p WorkBookProtection) CheckPassword(password string) bool {
+	return checkPassword(p.Password, password)
+}
+
+func checkPassword(verifier uint16, password string) bool {
+	if verifier == 0 {
+		return password == ""
+	}
+	return passwordVerifier(password) == verifier
+}
+
+func defaultSheetProtection() SheetProtection {
+	return SheetProtection{Allow: AllowSelectLockedCells | AllowSelectUnlockedCells}
+}
+
+// parseFeatHeader reads the enhanced protection of a FEATHEADR record.
+func (p *SheetProtection) parseFeatHeader(bts []byte) {
+	// Future record header, shared feature type, reserved byte and the
+	// size of the header data, which Excel writes as 0xFFFFFFFF for the
+	// protection flags. A size of zero means no header data.
+	if len(bts) < 23 || binary.LittleEndian.Uint16(bts[12:]) != 2 {
+		return
+	}
+	if binary.LittleEndian.Uint32(bts[15:]) == 0 {
+		return
+	}
+	p.Allow = ProtectionAllow(binary.LittleEndian.Uint32(bts[19:]) & 0x7FFF)
+}
+
+// CellProtection reports whether a zero-based cell is locked and whether
+// its formula is hidden while the sheet is protected. Empty cells take the
+// format of their row or column.
+func (w *WorkSheet) CellProtection(row, col int) (locked, hidden bool) {
+	xf := -1
+	if r := w.Row(row); r != nil {
+		if x, ok := r.cell(col).(xfIndexer); ok {
+			xf = int(x.xfIndex(uint16(col)))
+		} else if x, ok := r.XF(); ok {
+			xf = int(x)
+		}
+	}
+	if xf < 0 {
+		xf = int(w.Column(col).XF)
+	}
+	st, ok := w.wb.Style(xf)
+	if !ok {
+		// Cells are locked by default.
+		return true, false
+	}
+	return st.Locked, st.Hidden
+}
@@ -0,0 +1,87 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestSheetProtection(t *testing.T) {
+	wb := &WorkBook{
+		Formats: make(map[uint16]*Format),
+		XF:      []XF{&xf8{}, &xf8{Type: 0x3}},
+	}
+	locked := cat(le16(0, 1, 1), make([]byte, 8))
+	binary.LittleEndian.PutUint64(locked[6:], math.Float64bits(2))
+	allow := AllowSelectUnlockedCells | AllowFormatCells | AllowSort
+	s := sheetFromRecords(t, wb,
+		biffRecord(0x012, le16(1)),
+		biffRecord(0x063, le16(1)),
+		biffRecord(0x0DD, le16(0)),
+		biffRecord(0x013, le16(passwordVerifier("secret"))),
+		biffRecord(0x867, cat(le16(0x867), make([]byte, 10), le16(2), []byte{1}, le32(0xFFFFFFFF), le32(uint32(allow)))),
+		numberRecord(0, 0, 1),
+		biffRecord(0x203, locked),
+	)
+	p := s.Protection
+	if !p.Protected || !p.Objects || p.Scenarios || p.Allow != allow {
+		t.Errorf("protection = %+v", p)
+	}
+	if !p.CheckPassword("secret") || p.CheckPassword("Secret") || p.CheckPassword("") {
+		t.Errorf("password check failed for verifier %04X", p.Password)
+	}
+	for _, c := range []struct {
+		row, col       int
+		locked, hidden bool
+	}{{0, 0, false, false}, {0, 1, true, true}, {5, 5, true, false}} {
+		if l, h := s.CellProtection(c.row, c.col); l != c.locked || h != c.hidden {
+			t.Errorf("cell %d,%d: got locked %t hidden %t", c.row, c.col, l, h)
+		}
+	}
+
+	s = sheetFromRecords(t, nil)
+	if p := s.Protection; p.Protected || p.Allow != AllowSelectLockedCells|AllowSelectUnlockedCells || !p.CheckPassword("") {
+		t.Errorf("default protection = %+v", p)
+	}
+}
+
+func TestWorkBookProtection(t *testing.T) {
+	w := NewWriter()
+	w.AddSheet("Sheet1")
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, _ = insertRecords(stream,
+		biffRecord(0x012, le16(1)),
+		biffRecord(0x019, le16(0)),
+		biffRecord(0x013, le16(passwordVerifier("book"))),
+	)
+	// Sheet protection records must not count for the workbook.
+	var sheet int
+	for pos, bofs := 0, 0; bofs < 2; {
+		id, size := binary.LittleEndian.Uint16(stream[pos:]), int(binary.LittleEndian.Uint16(stream[pos+2:]))
+		pos += 4 + size
+		if id == 0x809 {
+			bofs++
+			sheet = pos
+		}
+	}
+	stream = cat(stream[:sheet], biffRecord(0x019, le16(1)), biffRecord(0x013, le16(0x1234)), stream[sheet:])
+	wb, err := OpenReader(bytes.NewReader(packWorkbook(t, stream)), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := wb.Protection
+	if !p.Structure || p.Windows || !p.CheckPassword("book") {
+		t.Errorf("protection = %+v", p)
+	}
+	s, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Protection.Password != 0x1234 {
+		t.Errorf("sheet password = %04X", s.Protection.Password)
+	}
+}
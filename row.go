@@ -10,6 +10,15 @@ type rowInfo struct {
 	Flags    uint32
 }
 
+// ROW record flags.
+const (
+	rowOutlineMask = 0x0007
+	rowCollapsed   = 0x0010
+	rowHidden      = 0x0020
+	rowCustom      = 0x0040
+	rowFormatted   = 0x0080
+)
+
 // Row handle.
 type Row struct {
 	wb   *WorkBook
@@ -60,6 +69,89 @@ func (r *Row) Value(n int) CellValue {
 	return CellValue{}
 }
 
+// Style of the cell in the n'th column (zero-based). It reports false if
+// there is no cell or the cell has no format.
+func (r *Row) Style(n int) (Style, bool) {
+	ch := r.cell(n)
+	x, ok := ch.(xfIndexer)
+	if !ok {
+		return Style{}, false
+	}
+	return r.wb.Style(int(x.xfIndex(uint16(n))))
+}
+
+// Font of the cell in the n'th column (zero-based), found through its style.
+func (r *Row) Font(n int) (Font, bool) {
+	st, ok := r.Style(n)
+	if !ok {
+		return Font{}, false
+	}
+	return r.wb.Font(st.Font)
+}
+
+// RichText of the string cell in the n'th column (zero-based). Only shared
+// strings carry formatting runs and phonetic text.
+func (r *Row) RichText(n int) (RichString, bool) {
+	switch c := r.cell(n).(type) {
+	case *LabelsstCol:
+		return r.wb.richString(c.Sst), true
+	case *labelCol:
+		return RichString{Text: c.Str}, true
+	case *FormulaStringCol:
+		return RichString{Text: c.RenderedValue}, true
+	}
+	return RichString{}, false
+}
+
+// cell returns the cell covering the n'th column.
+func (r *Row) cell(n int) contentHandler {
+	serial := uint16(n)
+	if ch, ok := r.cols[serial]; ok {
+		return ch
+	}
+	for _, v := range r.cols {
+		if v.FirstCol() <= serial && v.LastCol() >= serial {
+			return v
+		}
+	}
+	return nil
+}
+
+// Height of the row in points.
+func (r *Row) Height() float64 {
+	return float64(r.info.Height&0x7FFF) / 20
+}
+
+// CustomHeight reports if the height was set by the author rather than
+// derived from the row content.
+func (r *Row) CustomHeight() bool {
+	return r.info.Flags&rowCustom != 0
+}
+
+// Hidden reports if the row is hidden.
+func (r *Row) Hidden() bool {
+	return r.info.Flags&rowHidden != 0
+}
+
+// OutlineLevel is the grouping level of the row, 0 for ungrouped rows.
+func (r *Row) OutlineLevel() int {
+	return int(r.info.Flags & rowOutlineMask)
+}
+
+// Collapsed reports if the group below the row is collapsed.
+func (r *Row) Collapsed() bool {
+	return r.info.Flags&rowCollapsed != 0
+}
+
+// XF returns the format of empty cells in the row. It reports false if the
+// row has no format of its own.
+func (r *Row) XF() (uint16, bool) {
+	if r.info.Flags&rowFormatted == 0 {
+		return 0, false
+	}
+	return uint16(r.info.Flags >> 16 & 0xFFF), true
+}
+
 // LastCol gets the index of the last column.
 func (r *Row) LastCol() int {
 	return int(r.info.Lcell)
@@ -0,0 +1,221 @@
+package xls
+
+import (
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+)
+
+// FormatRun sets the font from a character of a rich string to the start
+// of the next run.
+type FormatRun struct {
+	Char int    // Index of the first character, counted in runes.
+	Font uint16 // Font index, see WorkBook.Font.
+}
+
+// RichString is a shared string with its formatting runs and phonetic text.
+type RichString struct {
+	Text string
+	Runs []FormatRun
+	// Phonetic is the Asian phonetic (furigana) text of the string.
+	Phonetic string
+}
+
+// TextSegment is a part of a rich string in a single font.
+type TextSegment struct {
+	Text string
+	Font uint16
+}
+
+// Segments splits the string at its formatting runs. Text before the first
+// run uses font, normally the font of the cell.
+func (s RichString) Segments(font uint16) []TextSegment {
+	runes := []rune(s.Text)
+	var segs []TextSegment
+	start := 0
+	for _, r := range s.Runs {
+		c := r.Char
+		if c > len(runes) {
+			c = len(runes)
+		}
+		if c > start {
+			segs = append(segs, TextSegment{Text: string(runes[start:c]), Font: font})
+			start = c
+		}
+		font = r.Font
+	}
+	if start < len(runes) || len(segs) == 0 {
+		segs = append(segs, TextSegment{Text: string(runes[start:]), Font: font})
+	}
+	return segs
+}
+
+// sstReader reads the shared string table from the SST record and the
+// CONTINUE records following it.
+type sstReader struct {
+	parts [][]byte
+	buf   []byte
+	err   error
+}
+
+func (r *sstReader) next() bool {
+	if len(r.parts) == 0 {
+		r.err = io.ErrUnexpectedEOF
+		return false
+	}
+	r.buf, r.parts = r.parts[0], r.parts[1:]
+	return true
+}
+
+// bytes reads n bytes, continuing in the next record as needed.
+func (r *sstReader) bytes(n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		if len(r.buf) == 0 && !r.next() {
+			break
+		}
+		k := n - len(out)
+		if k > len(r.buf) {
+			k = len(r.buf)
+		}
+		out = append(out, r.buf[:k]...)
+		r.buf = r.buf[k:]
+	}
+	return out
+}
+
+func (r *sstReader) uint16() uint16 {
+	if b := r.bytes(2); len(b) == 2 {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *sstReader) uint32() uint32 {
+	if b := r.bytes(4); len(b) == 4 {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// chars reads n characters. Characters continued in a new record are
+// preceded by a flag byte giving their width again.
+func (r *sstReader) chars(n int, wide bool) []uint16 {
+	out := make([]uint16, 0, n)
+	for len(out) < n {
+		if len(r.buf) == 0 {
+			if !r.next() {
+				break
+			}
+			if len(r.buf) > 0 {
+				wide = r.buf[0]&0x1 != 0
+				r.buf = r.buf[1:]
+			}
+			continue
+		}
+		if !wide {
+			out = append(out, uint16(r.buf[0]))
+			r.buf = r.buf[1:]
+			continue
+		}
+		if len(r.buf) < 2 {
+			r.buf = nil
+			continue
+		}
+		out = append(out, binary.LittleEndian.Uint16(r.buf))
+		r.buf = r.buf[2:]
+	}
+	return out
+}
+
+// parseSST decodes the shared strings collected from the SST record and
+// its CONTINUE records.
+func (w *WorkBook) parseSST() {
+	if len(w.sstParts) == 0 {
+		return
+	}
+	r := &sstReader{parts: w.sstParts}
+	w.sstParts = nil
+	size := 0
+	for _, p := range r.parts {
+		size += len(p)
+	}
+	r.next()
+	r.uint32() // Total references.
+	count := int(r.uint32())
+	// Each string takes at least three bytes.
+	if count > size/3 {
+		count = size / 3
+	}
+	w.sst = make([]string, count)
+	w.sstRich = make(map[uint32]*RichString)
+	for i := 0; i < count && r.err == nil; i++ {
+		cch := int(r.uint16())
+		var flags byte
+		if b := r.bytes(1); len(b) == 1 {
+			flags = b[0]
+		}
+		var runs uint16
+		var ext uint32
+		if flags&0x8 != 0 {
+			runs = r.uint16()
+		}
+		if flags&0x4 != 0 {
+			ext = r.uint32()
+		}
+		u := r.chars(cch, flags&0x1 != 0)
+		w.sst[i] = string(utf16.Decode(u))
+		if runs == 0 && ext == 0 {
+			continue
+		}
+		rs := &RichString{Text: w.sst[i]}
+		b := r.bytes(4 * int(runs))
+		for j := 0; j+4 <= len(b); j += 4 {
+			rs.Runs = append(rs.Runs, FormatRun{
+				Char: runeIndex(u, int(binary.LittleEndian.Uint16(b[j:]))),
+				Font: binary.LittleEndian.Uint16(b[j+2:]),
+			})
+		}
+		if ext > 0 {
+			rs.Phonetic = phoneticText(r.bytes(int(ext)))
+		}
+		w.sstRich[uint32(i)] = rs
+	}
+}
+
+// runeIndex converts an offset in UTF-16 code units to runes.
+func runeIndex(u []uint16, ich int) int {
+	if ich > len(u) {
+		ich = len(u)
+	}
+	return len(utf16.Decode(u[:ich]))
+}
+
+// phoneticText returns the phonetic string of an ExtRst structure.
+func phoneticText(b []byte) string {
+	// Reserved, size, phonetic settings, run count and character count
+	// come before the string.
+	if len(b) < 14 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint16(b[12:]))
+	if max := (len(b) - 14) / 2; n > max {
+		n = max
+	}
+	u := make([]uint16, n)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[14+2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// richString returns shared string i with its formatting.
+func (w *WorkBook) richString(i uint32) RichString {
+	if rs := w.sstRich[i]; rs != nil {
+		return *rs
+	}
+	if int(i) < len(w.sst) {
+		return RichString{Text: w.sst[i]}
+	}
+	return RichString{}
+}
@@ -0,0 +1,86 @@
+package xls
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func le16(v ...uint16) []byte {
+	var b []byte
+	for _, x := range v {
+		b = append(b, byte(x), byte(x>>8))
+	}
+	return b
+}
+
+func TestParseSST(t *testing.T) {
+	var sst, cont1, cont2 []byte
+	sst = append(sst, le32(3)...)
+	sst = append(sst, le32(3)...)
+
+	// "Hello World" with two runs, the characters continue as UTF-16 in
+	// the next record and the runs are split over the following one.
+	sst = append(sst, le16(11)...)
+	sst = append(sst, 0x08)
+	sst = append(sst, le16(2)...)
+	sst = append(sst, "Hello "...)
+	cont1 = append(cont1, 0x01)
+	cont1 = append(cont1, le16(utf16.Encode([]rune("World"))...)...)
+	cont1 = append(cont1, le16(0, 6)...)
+	cont2 = append(cont2, le16(6, 7)...)
+
+	// "東京" with phonetic text.
+	phonetic := utf16.Encode([]rune("とうきょう"))
+	ext := le16(1, uint16(10+2*len(phonetic)), 0, 0, 1, uint16(len(phonetic)), uint16(len(phonetic)))
+	ext = append(ext, le16(phonetic...)...)
+	ext = append(ext, le16(0, 0, 2)...)
+	cont2 = append(cont2, le16(2)...)
+	cont2 = append(cont2, 0x05)
+	cont2 = append(cont2, le32(uint32(len(ext)))...)
+	cont2 = append(cont2, le16(utf16.Encode([]rune("東京"))...)...)
+	cont2 = append(cont2, ext...)
+
+	// A string starting at a record boundary has no extra flag byte.
+	cont3 := le16(1)
+	cont3 = append(cont3, 0, 'x')
+
+	wb := &WorkBook{sstParts: [][]byte{sst, cont1, cont2, cont3}}
+	wb.parseSST()
+	if want := []string{"Hello World", "東京", "x"}; !reflect.DeepEqual(wb.sst, want) {
+		t.Fatalf("got %q, want %q", wb.sst, want)
+	}
+	hello := wb.richString(0)
+	if want := []FormatRun{{0, 6}, {6, 7}}; !reflect.DeepEqual(hello.Runs, want) {
+		t.Fatalf("got runs %+v", hello.Runs)
+	}
+	segs := hello.Segments(0)
+	if want := []TextSegment{{"Hello ", 6}, {"World", 7}}; !reflect.DeepEqual(segs, want) {
+		t.Fatalf("got segments %+v", segs)
+	}
+	if got := wb.richString(1); got.Phonetic != "とうきょう" || got.Text != "東京" || got.Runs != nil {
+		t.Fatalf("got %+v", got)
+	}
+	if got := wb.richString(2); !reflect.DeepEqual(got, RichString{Text: "x"}) {
+		t.Fatalf("got %+v", got)
+	}
+
+	row := &Row{wb: wb, cols: map[uint16]contentHandler{
+		1: &LabelsstCol{Col: Col{FirstColB: 1}, Sst: 1},
+		2: &NumberCol{Col: Col{FirstColB: 2}},
+	}}
+	if got, ok := row.RichText(1); !ok || got.Phonetic != "とうきょう" {
+		t.Fatalf("got %+v %t", got, ok)
+	}
+	if _, ok := row.RichText(2); ok {
+		t.Fatal("number cell has no rich text")
+	}
+}
+
+func TestSegments(t *testing.T) {
+	s := RichString{Text: "ab😀cd", Runs: []FormatRun{{2, 5}, {3, 6}, {10, 7}}}
+	want := []TextSegment{{"ab", 1}, {"😀", 5}, {"cd", 6}}
+	if got := s.Segments(1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v", got)
+	}
+}
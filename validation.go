@@ -0,0 +1,172 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// ValidationType is the kind of value allowed by a data validation.
+type ValidationType byte
+
+const (
+	ValidationAny ValidationType = iota
+	ValidationWhole
+	ValidationDecimal
+	ValidationList
+	ValidationDate
+	ValidationTime
+	ValidationTextLength
+	ValidationCustom
+)
+
+// Operator compares a value with the formulas of a data validation or
+// conditional format.
+type Operator byte
+
+const (
+	OperatorNone Operator = iota
+	OperatorBetween
+	OperatorNotBetween
+	OperatorEqual
+	OperatorNotEqual
+	OperatorGreater
+	OperatorLess
+	OperatorGreaterOrEqual
+	OperatorLessOrEqual
+)
+
+// ValidationErrorStyle is the alert shown for invalid values.
+type ValidationErrorStyle byte
+
+const (
+	ValidationStop ValidationErrorStyle = iota
+	ValidationWarning
+	ValidationInformation
+)
+
+// DataValidation is a rule restricting the values of cells.
+type DataValidation struct {
+	Type       ValidationType
+	Operator   Operator
+	ErrorStyle ValidationErrorStyle
+
+	AllowBlank bool
+	// ShowDropDown is set when list values are offered in a drop down.
+	ShowDropDown bool
+	ShowInput    bool
+	ShowError    bool
+
+	PromptTitle string
+	Prompt      string
+	ErrorTitle  string
+	Error       string
+
+	// Formula1 and Formula2 are the formulas the value is compared with,
+	// Formula2 only for the between operators.
+	Formula1 string
+	Formula2 string
+	// List holds the values of a list given explicitly rather than by a
+	// cell range in Formula1.
+	List []string
+
+	Ranges []CellRange
+}
+
+// contains reports whether the zero-based cell is in the range.
+func (c *CellRange) contains(row, col int) bool {
+	return row >= int(c.FirstRowB) && row <= int(c.LastRowB) &&
+		col >= int(c.FristColB) && col <= int(c.LastColB)
+}
+
+// DataValidation returns the validation of a zero-based cell.
+func (w *WorkSheet) DataValidation(row, col int) (DataValidation, bool) {
+	for _, dv := range w.DataValidations {
+		for i := range dv.Ranges {
+			if dv.Ranges[i].contains(row, col) {
+				return dv, true
+			}
+		}
+	}
+	return DataValidation{}, false
+}
+
+// readRanges reads a count and the ranges of a sqref structure.
+func readRanges(buf io.Reader) []CellRange {
+	var count uint16
+	binary.Read(buf, binary.LittleEndian, &count)
+	var ranges []CellRange
+	for i := uint16(0); i < count; i++ {
+		var cr CellRange
+		if binary.Read(buf, binary.LittleEndian, &cr) != nil {
+			break
+		}
+		ranges = append(ranges, cr)
+	}
+	return ranges
+}
+
+// readDVString reads a string of a DV record, where a single NUL character
+// stands for an empty string.
+func (w *WorkSheet) readDVString(buf io.ReadSeeker) string {
+	var cch uint16
+	if binary.Read(buf, binary.LittleEndian, &cch) != nil {
+		return ""
+	}
+	s, _ := w.wb.getString(buf, cch)
+	if s == "\x00" {
+		return ""
+	}
+	return s
+}
+
+// readDVFormula reads the size prefixed tokens of a DV formula.
+func readDVFormula(buf io.Reader) []byte {
+	var cce, unused uint16
+	binary.Read(buf, binary.LittleEndian, &cce)
+	binary.Read(buf, binary.LittleEndian, &unused)
+	rgce := make([]byte, cce)
+	n, _ := io.ReadFull(buf, rgce)
+	return rgce[:n]
+}
+
+// parseDV reads a DV record.
+func (w *WorkSheet) parseDV(bts []byte) DataValidation {
+	buf := bytes.NewReader(bts)
+	var flags uint32
+	binary.Read(buf, binary.LittleEndian, &flags)
+	dv := DataValidation{
+		Type:         ValidationType(flags & 0xF),
+		ErrorStyle:   ValidationErrorStyle(flags >> 4 & 0x7),
+		AllowBlank:   flags&0x100 != 0,
+		ShowDropDown: flags&0x200 == 0,
+		ShowInput:    flags&0x40000 != 0,
+		ShowError:    flags&0x80000 != 0,
+	}
+	if dv.Type != ValidationAny && dv.Type != ValidationList && dv.Type != ValidationCustom {
+		dv.Operator = Operator(flags>>20&0xF) + OperatorBetween
+	}
+	dv.PromptTitle = w.readDVString(buf)
+	dv.ErrorTitle = w.readDVString(buf)
+	dv.Prompt = w.readDVString(buf)
+	dv.Error = w.readDVString(buf)
+	f1 := readDVFormula(buf)
+	f2 := readDVFormula(buf)
+	dv.Ranges = readRanges(buf)
+
+	var base cellRef
+	if len(dv.Ranges) > 0 {
+		base = cellRef{int(dv.Ranges[0].FirstRowB), int(dv.Ranges[0].FristColB)}
+	}
+	if s, ok := formulaString(f1); ok && dv.Type == ValidationList && flags&0x80 != 0 {
+		dv.List = strings.Split(s, "\x00")
+		dv.Formula1 = `"` + strings.Join(dv.List, ",") + `"`
+	} else if len(f1) > 0 {
+		dv.Formula1, _ = formulaText(f1, nil, base, w.wb)
+	}
+	if len(f2) > 0 {
+		dv.Formula2, _ = formulaText(f2, nil, base, w.wb)
+	}
+	return dv
+}
@@ -0,0 +1,61 @@
+package xls
+
+import "testing"
+
+func dvString(s string) []byte {
+	if s == "" {
+		s = "\x00"
+	}
+	return mustXLString(s, 2)
+}
+
+func dvRecord(flags uint32, prompt string, f1, f2 []byte, ranges ...uint16) []byte {
+	b := cat(le32(flags), dvString(""), dvString("Invalid"), dvString(prompt), dvString("Pick one"))
+	b = cat(b, le16(uint16(len(f1)), 0), f1, le16(uint16(len(f2)), 0), f2)
+	return cat(b, le16(uint16(len(ranges)/4)), le16(ranges...))
+}
+
+func TestDataValidation(t *testing.T) {
+	list := dvRecord(0x3|0x80|0x100|0x40000|0x80000, "Choose", ptgStr("Red\x00Green\x00Blue"), nil, 1, 10, 2, 2)
+	whole := dvRecord(0x1|0x10|0x100000, "", cat([]byte{0x1E}, le16(1)), cat([]byte{0x1E}, le16(10)), 1, 1, 3, 4, 5, 5, 3, 3)
+	ref := dvRecord(0x3|0x200, "", cat([]byte{0x25}, le16(0, 4, 7, 7)), nil, 0, 0, 0, 0)
+	s := sheetFromRecords(t, nil,
+		biffRecord(0x1B2, make([]byte, 18)),
+		biffRecord(0x1BE, list),
+		biffRecord(0x1BE, whole),
+		biffRecord(0x1BE, ref),
+	)
+	if len(s.DataValidations) != 3 {
+		t.Fatalf("got %d validations, want 3", len(s.DataValidations))
+	}
+
+	dv, ok := s.DataValidation(5, 2)
+	if !ok {
+		t.Fatal("no validation for C6")
+	}
+	if dv.Type != ValidationList || !dv.AllowBlank || !dv.ShowDropDown || !dv.ShowInput || !dv.ShowError {
+		t.Errorf("list flags = %+v", dv)
+	}
+	if len(dv.List) != 3 || dv.List[1] != "Green" || dv.Formula1 != `"Red,Green,Blue"` {
+		t.Errorf("list = %q, formula %q", dv.List, dv.Formula1)
+	}
+	if dv.Prompt != "Choose" || dv.PromptTitle != "" || dv.ErrorTitle != "Invalid" || dv.Error != "Pick one" {
+		t.Errorf("messages = %+v", dv)
+	}
+
+	dv, ok = s.DataValidation(5, 3)
+	if !ok || dv.Type != ValidationWhole || dv.Operator != OperatorNotBetween || dv.ErrorStyle != ValidationWarning {
+		t.Errorf("whole = %+v", dv)
+	}
+	if dv.Formula1 != "1" || dv.Formula2 != "10" || len(dv.Ranges) != 2 {
+		t.Errorf("whole formulas = %q %q, ranges %v", dv.Formula1, dv.Formula2, dv.Ranges)
+	}
+
+	dv, _ = s.DataValidation(0, 0)
+	if dv.Formula1 != "$H$1:$H$5" || dv.List != nil || dv.ShowDropDown {
+		t.Errorf("range list = %+v", dv)
+	}
+	if _, ok := s.DataValidation(0, 1); ok {
+		t.Error("unexpected validation for B1")
+	}
+}
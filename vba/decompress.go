@@ -0,0 +1,87 @@
+package vba
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errSignature = errors.New("vba: bad compressed container signature")
+	errChunk     = errors.New("vba: bad compressed chunk")
+)
+
+// chunkSize is the size of a decompressed chunk.
+const chunkSize = 4096
+
+// Decompress decompresses a compressed container of MS-OVBA, such as the
+// dir stream or the source code of a module.
+func Decompress(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != 0x01 {
+		return nil, errSignature
+	}
+	var out []byte
+	for b = b[1:]; len(b) >= 2; {
+		header := binary.LittleEndian.Uint16(b)
+		if header>>12&0x7 != 0x3 {
+			return out, errChunk
+		}
+		size := int(header&0x0FFF) + 3
+		if size > len(b) {
+			size = len(b)
+		}
+		chunk := b[2:size]
+		b = b[size:]
+		if header&0x8000 == 0 {
+			// Raw chunks hold chunkSize bytes.
+			if len(chunk) != chunkSize {
+				return out, errChunk
+			}
+			out = append(out, chunk...)
+			continue
+		}
+		var err error
+		if out, err = decompressChunk(out, chunk); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// decompressChunk appends the decompressed data of a compressed chunk to
+// out. Each flag byte tells which of the following eight tokens are copy
+// tokens rather than literal bytes.
+func decompressChunk(out, chunk []byte) ([]byte, error) {
+	start := len(out)
+	for len(chunk) > 0 {
+		flags := chunk[0]
+		chunk = chunk[1:]
+		for i := uint(0); i < 8 && len(chunk) > 0; i++ {
+			if flags&(1<<i) == 0 {
+				out = append(out, chunk[0])
+				chunk = chunk[1:]
+				continue
+			}
+			if len(chunk) < 2 {
+				return out, errChunk
+			}
+			token := binary.LittleEndian.Uint16(chunk)
+			chunk = chunk[2:]
+			// The offset takes as many bits as needed to reach the start
+			// of the chunk, at least four.
+			bits := uint(4)
+			for 1<<bits < len(out)-start {
+				bits++
+			}
+			length := int(token&(0xFFFF>>bits)) + 3
+			offset := int(token>>(16-bits)) + 1
+			if offset > len(out)-start {
+				return out, errChunk
+			}
+			// The copy may overlap the bytes it produces.
+			for k := 0; k < length; k++ {
+				out = append(out, out[len(out)-offset])
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package vba
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// compress builds a compressed container of raw chunks and a last chunk of
+// literal tokens, which must be shorter than 3641 bytes.
+func compress(b []byte) []byte {
+	out := []byte{0x01}
+	for len(b) > 0 {
+		n := len(b)
+		if n >= chunkSize {
+			out = append(out, 0xFF, 0x3F)
+			out = append(out, b[:chunkSize]...)
+			b = b[chunkSize:]
+			continue
+		}
+		var chunk []byte
+		for i := 0; i < n; i += 8 {
+			j := i + 8
+			if j > n {
+				j = n
+			}
+			chunk = append(chunk, 0)
+			chunk = append(chunk, b[i:j]...)
+		}
+		size := uint16(len(chunk) + 2 - 3)
+		out = append(out, byte(size), byte(size>>8)|0xB0)
+		out = append(out, chunk...)
+		b = b[n:]
+	}
+	return out
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"no compression", "01 19 B0 00 61 62 63 64 65 66 67 68 00 69 6A 6B 6C 6D 6E 6F 70 00 71 72 73 74 75 76 2E", "abcdefghijklmnopqrstuv."},
+		{"normal compression", "01 2F B0 00 23 61 61 61 62 63 64 65 82 66 00 70 61 67 68 69 6A 01 38 08 61 6B 6C 00 30 6D 6E 6F 70 06 71 02 70 04 10 72 73 74 75 76 10 77 78 79 7A 00 3C", "#aaabcdefaaaaghijaaaaaklaaamnopqaaaaaaaaaaaarstuvwxyzaaa"},
+		{"maximum compression", "01 03 B0 02 61 45 00", strings.Repeat("a", 73)},
+	}
+	for _, tt := range tests {
+		in, err := hex.DecodeString(strings.Replace(tt.in, " ", "", -1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Decompress(in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	long := bytes.Repeat([]byte("Attribute VB_Name = \"Module1\"\r\n"), 300)
+	if got, err := Decompress(compress(long)); err != nil || !bytes.Equal(got, long) {
+		t.Errorf("multiple chunks: got %d bytes, %v", len(got), err)
+	}
+	raw := append([]byte{0x01, 0xFF, 0x3F}, bytes.Repeat([]byte{'x'}, chunkSize)...)
+	if got, err := Decompress(raw); err != nil || len(got) != chunkSize {
+		t.Errorf("raw chunk: got %d bytes, %v", len(got), err)
+	}
+	if _, err := Decompress([]byte{0x02, 0x00, 0xB0}); err != errSignature {
+		t.Errorf("got %v, want %v", err, errSignature)
+	}
+}
@@ -0,0 +1,194 @@
+// Package vba reads the VBA projects of Office documents as described in
+// MS-OVBA.
+package vba
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+// ModuleType tells procedural modules from the modules of classes,
+// documents and forms.
+type ModuleType byte
+
+const (
+	ModuleProcedural ModuleType = iota
+	ModuleClass
+)
+
+// Module is a module of a VBA project.
+type Module struct {
+	Name string
+	// StreamName is the name of the stream in the VBA storage holding the
+	// module.
+	StreamName string
+	DocString  string
+	Type       ModuleType
+	ReadOnly   bool
+	Private    bool
+	// Code is the decompressed source code.
+	Code string
+
+	offset uint32
+}
+
+// Project is a VBA project.
+type Project struct {
+	Name      string
+	DocString string
+	Codepage  uint16
+	// References are the names of the referenced libraries and projects.
+	References []string
+	Modules    []Module
+}
+
+var errDirTruncated = errors.New("vba: truncated dir stream")
+
+// Open reads the VBA project stored in a storage, such as the
+// _VBA_PROJECT_CUR storage of a workbook, with the source code of its
+// modules.
+func Open(o *ole2.Ole, storage *ole2.Entry) (*Project, error) {
+	dir := storage.Lookup("VBA/dir")
+	if dir == nil {
+		return nil, fmt.Errorf("vba: %q has no dir stream", storage.Path)
+	}
+	b, err := o.ReadEntry(dir)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = Decompress(b); err != nil {
+		return nil, err
+	}
+	p, err := parseDir(b)
+	if err != nil {
+		return nil, err
+	}
+	for i := range p.Modules {
+		m := &p.Modules[i]
+		e := storage.Lookup("VBA/" + m.StreamName)
+		if e == nil {
+			return nil, fmt.Errorf("vba: module stream %q not found", m.StreamName)
+		}
+		b, err := o.ReadEntry(e)
+		if err != nil {
+			return nil, err
+		}
+		if int64(m.offset) > int64(len(b)) {
+			return nil, fmt.Errorf("vba: module %q offset out of range", m.Name)
+		}
+		code, err := Decompress(b[m.offset:])
+		if err != nil {
+			return nil, fmt.Errorf("vba: module %q: %w", m.Name, err)
+		}
+		m.Code = ole2.DecodeString(code, p.Codepage)
+	}
+	return p, nil
+}
+
+// dir stream record identifiers.
+const (
+	recCodepage          = 0x03
+	recName              = 0x04
+	recDocString         = 0x05
+	recVersion           = 0x09
+	recReferenceName     = 0x16
+	recModuleName        = 0x19
+	recModuleStreamName  = 0x1A
+	recModuleDocString   = 0x1C
+	recModuleProcedural  = 0x21
+	recModuleClass       = 0x22
+	recModuleReadOnly    = 0x25
+	recModulePrivate     = 0x28
+	recModuleTerminator  = 0x2B
+	recModuleOffset      = 0x31
+	recModuleStreamNameU = 0x32
+	recDocStringU        = 0x40
+	recModuleNameU       = 0x47
+	recModuleDocStringU  = 0x48
+	recTerminator        = 0x10
+)
+
+// parseDir reads the decompressed dir stream. Records are an identifier,
+// a size and the data, but for the version record whose size is 4 while
+// 6 bytes of data follow. Unicode records follow their code page
+// counterparts and replace them.
+func parseDir(b []byte) (*Project, error) {
+	p := &Project{Codepage: 1252}
+	var m *Module
+	for {
+		if len(b) < 6 {
+			return nil, errDirTruncated
+		}
+		id := binary.LittleEndian.Uint16(b)
+		size := int(binary.LittleEndian.Uint32(b[2:]))
+		if id == recVersion {
+			size = 6
+		}
+		if size > len(b)-6 {
+			return nil, errDirTruncated
+		}
+		data := b[6 : 6+size]
+		b = b[6+size:]
+		str := func() string { return ole2.DecodeString(data, p.Codepage) }
+		switch id {
+		case recTerminator:
+			return p, nil
+		case recCodepage:
+			if len(data) >= 2 {
+				p.Codepage = binary.LittleEndian.Uint16(data)
+			}
+		case recName:
+			p.Name = str()
+		case recDocString:
+			p.DocString = str()
+		case recDocStringU:
+			p.DocString = utf16String(data)
+		case recReferenceName:
+			p.References = append(p.References, str())
+		case recModuleName:
+			p.Modules = append(p.Modules, Module{Name: str()})
+			m = &p.Modules[len(p.Modules)-1]
+		}
+		if m == nil {
+			continue
+		}
+		switch id {
+		case recModuleNameU:
+			m.Name = utf16String(data)
+		case recModuleStreamName:
+			m.StreamName = str()
+		case recModuleStreamNameU:
+			m.StreamName = utf16String(data)
+		case recModuleDocString:
+			m.DocString = str()
+		case recModuleDocStringU:
+			m.DocString = utf16String(data)
+		case recModuleOffset:
+			if len(data) >= 4 {
+				m.offset = binary.LittleEndian.Uint32(data)
+			}
+		case recModuleProcedural:
+			m.Type = ModuleProcedural
+		case recModuleClass:
+			m.Type = ModuleClass
+		case recModuleReadOnly:
+			m.ReadOnly = true
+		case recModulePrivate:
+			m.Private = true
+		case recModuleTerminator:
+			m = nil
+		}
+	}
+}
+
+func utf16String(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
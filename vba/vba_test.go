@@ -0,0 +1,118 @@
+package vba
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+func dirRecord(id uint16, data []byte) []byte {
+	b := make([]byte, 6, 6+len(data))
+	binary.LittleEndian.PutUint16(b, id)
+	binary.LittleEndian.PutUint32(b[2:], uint32(len(data)))
+	return append(b, data...)
+}
+
+func le(v uint32, n int) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b[:n]
+}
+
+func utf16Bytes(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = append(b, le(uint32(c), 2)...)
+	}
+	return b
+}
+
+func moduleRecords(name, stream string, class bool, offset uint32) []byte {
+	typ := uint16(recModuleProcedural)
+	if class {
+		typ = recModuleClass
+	}
+	return bytes.Join([][]byte{
+		dirRecord(recModuleName, []byte(name)),
+		dirRecord(recModuleNameU, utf16Bytes(name)),
+		dirRecord(recModuleStreamName, []byte(stream)),
+		dirRecord(recModuleStreamNameU, utf16Bytes(stream)),
+		dirRecord(recModuleDocString, nil),
+		dirRecord(recModuleDocStringU, nil),
+		dirRecord(recModuleOffset, le(offset, 4)),
+		dirRecord(0x1E, le(0, 4)),
+		dirRecord(0x2C, le(0xFFFF, 2)),
+		dirRecord(typ, nil),
+		dirRecord(recModuleTerminator, nil),
+	}, nil)
+}
+
+func TestOpen(t *testing.T) {
+	// The version record claims 4 bytes but holds 6.
+	version := append(dirRecord(recVersion, le(0x1234, 4)), le(7, 2)...)
+	dir := bytes.Join([][]byte{
+		dirRecord(0x01, le(1, 4)),
+		dirRecord(0x02, le(0x409, 4)),
+		dirRecord(0x14, le(0x409, 4)),
+		dirRecord(recCodepage, le(1252, 2)),
+		dirRecord(recName, []byte("VBAProject")),
+		dirRecord(recDocString, []byte("Caf\xe9")),
+		dirRecord(recDocStringU, utf16Bytes("Café")),
+		dirRecord(0x06, nil),
+		dirRecord(0x3D, nil),
+		dirRecord(0x07, le(0, 4)),
+		dirRecord(0x08, le(0, 4)),
+		version,
+		dirRecord(0x0C, nil),
+		dirRecord(0x3C, nil),
+		dirRecord(recReferenceName, []byte("stdole")),
+		dirRecord(0x3E, utf16Bytes("stdole")),
+		dirRecord(0x0D, le(0, 4)),
+		dirRecord(0x0F, le(2, 2)),
+		dirRecord(0x13, le(0xFFFF, 2)),
+		moduleRecords("ThisWorkbook", "ThisWorkbook", true, 0),
+		moduleRecords("Module1", "Module1", false, 5),
+		dirRecord(recTerminator, nil),
+	}, nil)
+
+	code := "Attribute VB_Name = \"Module1\"\r\nSub Auto_Open()\r\n    Shell \"calc.exe\"\r\nEnd Sub\r\n"
+	cfb := ole2.NewWriter()
+	vba := cfb.Root.AddStorage("_VBA_PROJECT_CUR").AddStorage("VBA")
+	vba.AddStream("dir", compress(dir))
+	vba.AddStream("ThisWorkbook", compress([]byte("Attribute VB_Name = \"ThisWorkbook\"\r\n")))
+	vba.AddStream("Module1", append([]byte("pcode"), compress([]byte(code))...))
+	buf := &bytes.Buffer{}
+	if _, err := cfb.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := ole2.Open(bytes.NewReader(buf.Bytes()), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := o.Lookup("_VBA_PROJECT_CUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Open(o, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "VBAProject" || p.DocString != "Café" || p.Codepage != 1252 || len(p.References) != 1 || p.References[0] != "stdole" {
+		t.Errorf("project = %+v", p)
+	}
+	if len(p.Modules) != 2 {
+		t.Fatalf("got %d modules", len(p.Modules))
+	}
+	m := p.Modules[0]
+	if m.Name != "ThisWorkbook" || m.Type != ModuleClass || m.Code != "Attribute VB_Name = \"ThisWorkbook\"\r\n" {
+		t.Errorf("module 0 = %+v", m)
+	}
+	m = p.Modules[1]
+	if m.Name != "Module1" || m.StreamName != "Module1" || m.Type != ModuleProcedural || m.Code != code {
+		t.Errorf("module 1 = %+v", m)
+	}
+}
@@ -0,0 +1,184 @@
+package xls
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Panes of a split or frozen window.
+const (
+	PaneBottomRight = 0
+	PaneTopRight    = 1
+	PaneBottomLeft  = 2
+	PaneTopLeft     = 3
+)
+
+// SheetView holds the window settings of a worksheet.
+type SheetView struct {
+	// FirstRow and FirstCol are the top left visible cell.
+	FirstRow uint16
+	FirstCol uint16
+
+	ShowFormulas     bool
+	ShowGridlines    bool
+	ShowHeaders      bool
+	ShowZeros        bool
+	ShowOutline      bool
+	RightToLeft      bool
+	PageBreakPreview bool
+	// GridColor is the palette index of the gridlines.
+	GridColor uint16
+	// Zoom is the magnification in percent.
+	Zoom int
+
+	// Frozen is set when FrozenRows and FrozenCols are kept in view,
+	// otherwise SplitX and SplitY are the split positions in twips.
+	Frozen     bool
+	FrozenRows int
+	FrozenCols int
+	SplitX     int
+	SplitY     int
+	// PaneFirstRow and PaneFirstCol are the top left visible cell of the
+	// bottom right pane.
+	PaneFirstRow uint16
+	PaneFirstCol uint16
+	ActivePane   byte
+
+	// ActiveRow and ActiveCol are the active cell of the active pane and
+	// Selection the cells selected with it.
+	ActiveRow uint16
+	ActiveCol uint16
+	Selection []CellRange
+
+	// TabColor is the palette index of the sheet tab, -1 if not set.
+	TabColor int
+}
+
+type selection struct {
+	pane     byte
+	row, col uint16
+	refs     []CellRange
+}
+
+func defaultSheetView() SheetView {
+	return SheetView{
+		ShowGridlines: true,
+		ShowHeaders:   true,
+		ShowZeros:     true,
+		ShowOutline:   true,
+		GridColor:     ColorWindowText,
+		Zoom:          100,
+		ActivePane:    PaneTopLeft,
+		TabColor:      -1,
+	}
+}
+
+// parseWindow2 reads the WINDOW2 record.
+func (v *SheetView) parseWindow2(buf io.Reader, biff5 bool) {
+	var opt, row, col, color uint16
+	binary.Read(buf, binary.LittleEndian, &opt)
+	binary.Read(buf, binary.LittleEndian, &row)
+	binary.Read(buf, binary.LittleEndian, &col)
+	v.FirstRow, v.FirstCol = row, col
+	v.ShowFormulas = opt&0x1 != 0
+	v.ShowGridlines = opt&0x2 != 0
+	v.ShowHeaders = opt&0x4 != 0
+	v.Frozen = opt&0x8 != 0
+	v.ShowZeros = opt&0x10 != 0
+	v.RightToLeft = opt&0x40 != 0
+	v.ShowOutline = opt&0x80 != 0
+	v.PageBreakPreview = opt&0x800 != 0
+	if biff5 {
+		// BIFF5 stores an RGB grid color.
+		return
+	}
+	if binary.Read(buf, binary.LittleEndian, &color) == nil && opt&0x20 == 0 {
+		v.GridColor = color
+	}
+	var reserved, pageZoom, zoom uint16
+	binary.Read(buf, binary.LittleEndian, &reserved)
+	binary.Read(buf, binary.LittleEndian, &pageZoom)
+	if binary.Read(buf, binary.LittleEndian, &zoom) == nil && zoom != 0 {
+		v.Zoom = int(zoom)
+	}
+}
+
+// parsePane reads the PANE record.
+func (v *SheetView) parsePane(buf io.Reader) {
+	var pane struct {
+		X, Y     uint16
+		Row, Col uint16
+		Active   byte
+	}
+	if binary.Read(buf, binary.LittleEndian, &pane) != nil {
+		return
+	}
+	if v.Frozen {
+		v.FrozenCols, v.FrozenRows = int(pane.X), int(pane.Y)
+	} else {
+		v.SplitX, v.SplitY = int(pane.X), int(pane.Y)
+	}
+	v.PaneFirstRow, v.PaneFirstCol = pane.Row, pane.Col
+	v.ActivePane = pane.Active
+}
+
+// parseScl reads the SCL zoom record.
+func (v *SheetView) parseScl(buf io.Reader) {
+	var num, den uint16
+	binary.Read(buf, binary.LittleEndian, &num)
+	if binary.Read(buf, binary.LittleEndian, &den) == nil && den != 0 {
+		v.Zoom = int(num) * 100 / int(den)
+	}
+}
+
+// parseSelection reads a SELECTION record.
+func parseSelection(buf io.Reader) (s selection) {
+	var head struct {
+		Pane     byte
+		Row, Col uint16
+		Ref      uint16
+		Count    uint16
+	}
+	if binary.Read(buf, binary.LittleEndian, &head) != nil {
+		return
+	}
+	s.pane, s.row, s.col = head.Pane, head.Row, head.Col
+	for i := uint16(0); i < head.Count; i++ {
+		var ref struct {
+			FirstRow, LastRow uint16
+			FirstCol, LastCol byte
+		}
+		if binary.Read(buf, binary.LittleEndian, &ref) != nil {
+			break
+		}
+		s.refs = append(s.refs, CellRange{
+			FirstRowB: ref.FirstRow,
+			LastRowB:  ref.LastRow,
+			FristColB: uint16(ref.FirstCol),
+			LastColB:  uint16(ref.LastCol),
+		})
+	}
+	return
+}
+
+// parseSheetExt reads the tab color of the SHEETEXT record.
+func (v *SheetView) parseSheetExt(bts []byte) {
+	// Future record header and size come first. Index 0x7F is the default
+	// color, which means the tab is not colored.
+	if len(bts) >= 20 {
+		v.TabColor = int(binary.LittleEndian.Uint32(bts[16:]) & 0x7F)
+		if v.TabColor == 0x7F {
+			v.TabColor = -1
+		}
+	}
+}
+
+// setSelection sets the active cell from the selection of the active pane.
+func (v *SheetView) setSelection(sels []selection) {
+	for _, s := range sels {
+		if s.pane == v.ActivePane {
+			v.ActiveRow, v.ActiveCol = s.row, s.col
+			v.Selection = s.refs
+		}
+	}
+}
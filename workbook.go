@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"image/color"
 	"io"
 	"time"
 	"unicode/utf16"
 
+	"github.com/kardianos/xls/ole2"
 	"golang.org/x/text/encoding/charmap"
 )
 
@@ -20,42 +22,68 @@ type WorkBook struct {
 	Fonts    []Font
 	Formats  map[uint16]*Format
 	//All the sheets from the workbook
-	sheets         []*WorkSheet
-	Author         string
-	rs             io.ReadSeeker
-	sst            []string
-	continue_utf16 uint16
-	continue_rich  uint16
-	continue_apsb  uint32
-	dateMode       uint16
-	closer         io.Closer
+	sheets     []*WorkSheet
+	Author     string
+	Properties Properties
+	Names      []DefinedName
+	// ExternalBooks are the workbooks referenced by formulas, with the
+	// workbook itself and add-in functions.
+	ExternalBooks []ExternalBook
+	Protection    WorkBookProtection
+	// Encrypted is set when the workbook stream was decrypted.
+	Encrypted bool
+	password  string
+	rs        io.ReadSeeker
+	sst       []string
+	sstRich   map[uint32]*RichString
+	sstParts  [][]byte
+	dateMode  uint16
+	palette   []color.RGBA
+	// drawingGroup collects the MSODRAWINGGROUP records until parsed
+	// into blips.
+	drawingGroup []byte
+	blips        []*blip
+	externSheets []xti
+	// inSheet is set while the records of a sheet are read.
+	inSheet bool
+	// xctSheet is the sheet of the following CRN records.
+	xctSheet int
+	// ole and root give access to the other storages of the file.
+	ole    *ole2.Ole
+	root   *ole2.Entry
+	closer io.Closer
 }
 
 func (wb *WorkBook) ToDateTime(f float64) time.Time {
 	return timeFromExcelTime(f, wb.dateMode == 1)
 }
 
-type sstInfo struct {
-	Total uint32
-	Count uint32
-}
-
 // read workbook from ole2 file
-func newWorkBookFromOle2(rs io.ReadSeeker) (*WorkBook, error) {
+func newWorkBookFromOle2(rs io.ReadSeeker, password string) (*WorkBook, error) {
 	wb := &WorkBook{
-		Formats: make(map[uint16]*Format),
-		rs:      rs,
-		sheets:  make([]*WorkSheet, 0),
+		Formats:  make(map[uint16]*Format),
+		rs:       rs,
+		sheets:   make([]*WorkSheet, 0),
+		password: password,
 	}
 	err := wb.parse()
 	return wb, err
 }
 
 func (w *WorkBook) parse() error {
+	err := w.parseRecords()
+	w.parseSST()
+	w.resolveFonts()
+	w.resolveNames()
+	w.parseDrawingGroup()
+	return err
+}
+
+func (w *WorkBook) parseRecords() error {
 	b := new(bof)
 	bofPre := new(bof)
 
-	offset := 0
+	var pos int64
 	for {
 		err := binary.Read(w.rs, binary.LittleEndian, b)
 		if err != nil {
@@ -67,7 +95,18 @@ func (w *WorkBook) parse() error {
 			}
 			return err
 		}
-		bofPre, b, offset, err = w.parseBof(b, bofPre, offset)
+		pos += 4 + int64(b.Size)
+		if b.ID == 0x2F { // FILEPASS
+			body := make([]byte, b.Size)
+			if _, err := io.ReadFull(w.rs, body); err != nil {
+				return err
+			}
+			if err := w.decrypt(body, pos); err != nil {
+				return err
+			}
+			continue
+		}
+		bofPre, b, err = w.parseBof(b, bofPre)
 		if err == io.EOF {
 			err = nil
 		}
@@ -77,7 +116,7 @@ func (w *WorkBook) parse() error {
 	}
 }
 
-func (w *WorkBook) parseBof(b, pre *bof, offsetPre int) (after *bof, afterUsing *bof, offset int, err error) {
+func (w *WorkBook) parseBof(b, pre *bof) (after *bof, afterUsing *bof, err error) {
 	after = b
 	afterUsing = pre
 	var bts = make([]byte, b.Size)
@@ -94,56 +133,22 @@ func (w *WorkBook) parseBof(b, pre *bof, offsetPre int) (after *bof, afterUsing
 			w.Is5ver = true
 		}
 		w.Type = bif.Type
+		w.inSheet = bif.Type != 0x5
 	case 0x042: // CODEPAGE
 		binary.Read(bufItem, binary.LittleEndian, &w.Codepage)
 	case 0x3c: // CONTINUE
-		if pre.ID == 0xfc {
-			var size uint16
-			if w.continue_utf16 >= 1 {
-				size = w.continue_utf16
-				w.continue_utf16 = 0
-			} else {
-				err = binary.Read(bufItem, binary.LittleEndian, &size)
-			}
-			for err == nil && offsetPre < len(w.sst) {
-				var str string
-				str, err = w.getString(bufItem, size)
-				w.sst[offsetPre] = w.sst[offsetPre] + str
-
-				if err == io.EOF {
-					err = nil
-					break
-				}
-
-				offsetPre++
-				err = binary.Read(bufItem, binary.LittleEndian, &size)
-			}
+		switch pre.ID {
+		case 0xfc:
+			w.sstParts = append(w.sstParts, bts)
+		case 0xeb:
+			w.drawingGroup = append(w.drawingGroup, bts...)
 		}
-		offset = offsetPre
 		after = pre
 		afterUsing = b
+	case 0xeb: // MSODRAWINGGROUP
+		w.drawingGroup = append(w.drawingGroup, bts...)
 	case 0xfc: // SST
-		info := new(sstInfo)
-		binary.Read(bufItem, binary.LittleEndian, info)
-		w.sst = make([]string, info.Count)
-		var size uint16
-		var i = 0
-		// Initialize offset.
-		offset = 0
-		for ; i < int(info.Count); i++ {
-			err = binary.Read(bufItem, binary.LittleEndian, &size)
-			if err == nil {
-				var str string
-				str, err = w.getString(bufItem, size)
-				w.sst[i] = w.sst[i] + str
-			}
-
-			if err == io.EOF {
-				err = nil
-				break
-			}
-		}
-		offset = i
+		w.sstParts = [][]byte{bts}
 	case 0x85: // boundsheet
 		var bs = new(boundsheet)
 		binary.Read(bufItem, binary.LittleEndian, bs)
@@ -155,6 +160,7 @@ func (w *WorkBook) parseBof(b, pre *bof, offsetPre int) (after *bof, afterUsing
 			Name:       name,
 			wb:         w,
 			Visibility: TWorkSheetVisibility(bs.Visible),
+			Type:       SheetType(bs.Type),
 		})
 	case 0x0e0: // XF
 		if w.Is5ver {
@@ -167,18 +173,7 @@ func (w *WorkBook) parseBof(b, pre *bof, offsetPre int) (after *bof, afterUsing
 			w.XF = append(w.XF, xf)
 		}
 	case 0x031: // Font
-		f := new(FontInfo)
-		binary.Read(bufItem, binary.LittleEndian, f)
-		var name string
-		name, err = w.getString(bufItem, uint16(f.NameB))
-		if err == io.EOF {
-			err = nil
-		}
-		if err != nil {
-			err = fmt.Errorf("font getString: %w", err)
-			return
-		}
-		w.Fonts = append(w.Fonts, Font{Info: f, Name: name})
+		w.Fonts = append(w.Fonts, w.parseFont(bts))
 	case 0x41E: // Format
 		f := new(Format)
 		binary.Read(bufItem, binary.LittleEndian, &f.Head)
@@ -196,6 +191,43 @@ func (w *WorkBook) parseBof(b, pre *bof, offsetPre int) (after *bof, afterUsing
 			err = fmt.Errorf("format index %d already found", index)
 		}
 		w.Formats[index] = f
+	case 0x092: // PALETTE
+		var count uint16
+		binary.Read(bufItem, binary.LittleEndian, &count)
+		w.palette = make([]color.RGBA, 0, count)
+		for i := uint16(0); i < count; i++ {
+			var c [4]byte
+			if binary.Read(bufItem, binary.LittleEndian, &c) != nil {
+				break
+			}
+			w.palette = append(w.palette, color.RGBA{R: c[0], G: c[1], B: c[2], A: 0xFF})
+		}
+	case 0x018: // NAME
+		w.parseName(bts)
+	case 0x012: // PROTECT
+		if !w.inSheet && len(bts) >= 2 {
+			w.Protection.Structure = bts[0] != 0
+		}
+	case 0x019: // WINDOWPROTECT
+		if !w.inSheet && len(bts) >= 2 {
+			w.Protection.Windows = bts[0] != 0
+		}
+	case 0x013: // PASSWORD
+		if !w.inSheet && len(bts) >= 2 {
+			w.Protection.Password = binary.LittleEndian.Uint16(bts)
+		}
+	case 0x1AE: // SUPBOOK
+		w.parseSupBook(bts)
+	case 0x017: // EXTERNSHEET
+		w.parseExternSheet(bts)
+	case 0x023: // EXTERNNAME
+		w.parseExternName(bts)
+	case 0x059: // XCT
+		if len(bts) >= 4 {
+			w.xctSheet = int(binary.LittleEndian.Uint16(bts[2:]))
+		}
+	case 0x05A: // CRN
+		w.parseCRN(bts)
 	case 0x22: // DateMode
 		binary.Read(bufItem, binary.LittleEndian, &w.dateMode)
 	}
@@ -214,86 +246,57 @@ func (w *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err er
 			return
 		}
 		res = decodeWindows1251(bts)
-	} else {
-		richtextNum := uint16(0)
-		phoneticSize := uint32(0)
-		var flag byte
-		err = binary.Read(buf, binary.LittleEndian, &flag)
-		if flag&0x8 != 0 {
-			err = binary.Read(buf, binary.LittleEndian, &richtextNum)
-		} else if w.continue_rich > 0 {
-			richtextNum = w.continue_rich
-			w.continue_rich = 0
-		}
-		if flag&0x4 != 0 {
-			err = binary.Read(buf, binary.LittleEndian, &phoneticSize)
-		} else if w.continue_apsb > 0 {
-			phoneticSize = w.continue_apsb
-			w.continue_apsb = 0
-		}
-		if flag&0x1 != 0 {
-			var bts = make([]uint16, size)
-			var i = uint16(0)
-			for ; i < size && err == nil; i++ {
-				err = binary.Read(buf, binary.LittleEndian, &bts[i])
-			}
-
-			// When eof found, we dont want to append last element.
-			var runes []rune
-			if err == io.EOF {
-				i = i - 1
-			}
-			runes = utf16.Decode(bts[:i])
-
-			res = string(runes)
-			if i < size {
-				w.continue_utf16 = size - i
-			}
-
+		return
+	}
+	richtextNum := uint16(0)
+	phoneticSize := uint32(0)
+	var flag byte
+	if err = binary.Read(buf, binary.LittleEndian, &flag); err != nil {
+		return
+	}
+	if flag&0x8 != 0 {
+		err = binary.Read(buf, binary.LittleEndian, &richtextNum)
+	}
+	if flag&0x4 != 0 {
+		err = binary.Read(buf, binary.LittleEndian, &phoneticSize)
+	}
+	width := 1
+	if flag&0x1 != 0 {
+		width = 2
+	}
+	bts := make([]byte, int(size)*width)
+	n, rerr := io.ReadFull(buf, bts)
+	if rerr != nil {
+		// Return what was read of a truncated string.
+		err = io.EOF
+	}
+	var u = make([]uint16, n/width)
+	for i := range u {
+		if width == 2 {
+			u[i] = binary.LittleEndian.Uint16(bts[2*i:])
 		} else {
-			var bts = make([]byte, size)
-			var n int
-			n, err = buf.Read(bts)
-			if uint16(n) < size {
-				w.continue_utf16 = size - uint16(n)
-				err = io.EOF
-			}
-
-			var bts1 = make([]uint16, n)
-			for k, v := range bts[:n] {
-				bts1[k] = uint16(v)
-			}
-			runes := utf16.Decode(bts1)
-			res = string(runes)
-		}
-		if richtextNum > 0 {
-			var bts []byte
-			var seekSize int64
-			if w.Is5ver {
-				seekSize = int64(2 * richtextNum)
-			} else {
-				seekSize = int64(4 * richtextNum)
-			}
-			bts = make([]byte, seekSize)
-			err = binary.Read(buf, binary.LittleEndian, bts)
-			if err == io.EOF {
-				w.continue_rich = richtextNum
-			}
-		}
-		if phoneticSize > 0 {
-			var bts []byte
-			bts = make([]byte, phoneticSize)
-			err = binary.Read(buf, binary.LittleEndian, bts)
-			if err == io.EOF {
-				w.continue_apsb = phoneticSize
-			}
+			u[i] = uint16(bts[i])
 		}
 	}
+	res = string(utf16.Decode(u))
+	if err != nil {
+		return
+	}
+	// Formatting runs and phonetic data are only kept for shared strings.
+	if skip := int64(richtextNum)*4 + int64(phoneticSize); skip > 0 {
+		_, err = buf.Seek(skip, io.SeekCurrent)
+	}
 	return
 }
 
 // Reading a sheet from the compress file to memory, you should call this before you try to get anything from sheet.
 func (w *WorkBook) prepareSheet(sheet *WorkSheet) error {
+	if sheet.Type == SheetVBModule {
+		// The code of VB modules is kept in the VBA project.
+		sheet.rows = make(map[uint16]*Row)
+		sheet.parsed = true
+		return nil
+	}
 	_, err := w.rs.Seek(int64(sheet.bs.Filepos), 0)
 	if err != nil {
 		return err
@@ -316,6 +319,23 @@ func (w *WorkBook) GetSheet(num int) (*WorkSheet, error) {
 	return s, nil
 }
 
+// Worksheets returns the worksheets of the workbook, leaving out chart
+// sheets, macro sheets and VB modules.
+func (w *WorkBook) Worksheets() ([]*WorkSheet, error) {
+	var sheets []*WorkSheet
+	for i, s := range w.sheets {
+		if s.Type != SheetWorksheet {
+			continue
+		}
+		s, err := w.GetSheet(i)
+		if err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, s)
+	}
+	return sheets, nil
+}
+
 // NumSheets gets the number of all sheets, look into example.
 func (w *WorkBook) NumSheets() int {
 	return len(w.sheets)
@@ -1,10 +1,13 @@
 package xls
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/kardianos/xls/ole2"
 )
 
 func TestReadAll(t *testing.T) {
@@ -35,3 +38,94 @@ func TestReadAll(t *testing.T) {
 		t.Fatalf("incorrect data, got: %s", gotData)
 	}
 }
+
+func TestProperties(t *testing.T) {
+	wb, err := Open(filepath.Join("testdata", "table.xls"), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Close()
+	p := wb.Properties
+	if wb.Author != "huangxiong" || p.Author != "huangxiong" {
+		t.Fatalf("got author %q", p.Author)
+	}
+	if p.LastSavedBy != "Microsoft Office 用户" {
+		t.Fatalf("got last saved by %q", p.LastSavedBy)
+	}
+	if p.Application != "Microsoft Macintosh Excel" {
+		t.Fatalf("got application %q", p.Application)
+	}
+	if p.Created.Year() != 2015 || !p.Modified.After(p.Created) {
+		t.Fatalf("got times %v %v", p.Created, p.Modified)
+	}
+}
+
+func TestSheetTypes(t *testing.T) {
+	sheet := cat(
+		biffRecord(0x809, cat(le16(0x600, 0x10), make([]byte, 12))),
+		numberRecord(0, 0, 1),
+		biffRecord(0x0A, nil),
+	)
+	stream := cat(sheet, chartRecords(0x1019, 0, 0))
+	wb := &WorkBook{Formats: make(map[uint16]*Format), rs: bytes.NewReader(stream)}
+	for _, s := range []struct {
+		pos uint32
+		typ SheetType
+	}{{0, SheetWorksheet}, {uint32(len(sheet)), SheetChart}, {0, SheetVBModule}} {
+		wb.sheets = append(wb.sheets, &WorkSheet{wb: wb, bs: &boundsheet{Filepos: s.pos}, Type: s.typ})
+	}
+	sheets, err := wb.Worksheets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheets) != 1 || sheets[0] != wb.sheets[0] || sheets[0].Row(0) == nil {
+		t.Fatalf("got worksheets %v", sheets)
+	}
+	chart, err := wb.GetSheet(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Charts) != 1 || chart.Charts[0].Type != ChartPie || chart.Row(0) != nil {
+		t.Errorf("chart sheet = %+v", chart.Charts)
+	}
+	module, err := wb.GetSheet(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if module.MaxRow != 0 || module.Row(0) != nil {
+		t.Errorf("module sheet has cells")
+	}
+}
+
+func TestVBAProject(t *testing.T) {
+	w := NewWriter()
+	w.AddSheet("Sheet1")
+	stream, err := w.workbookStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, withVBA := range []bool{false, true} {
+		cfb := ole2.NewWriter()
+		cfb.Root.AddStream("Workbook", stream)
+		if withVBA {
+			// A dir stream holding only its terminator.
+			dir := []byte{0x01, 0x06, 0xB0, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00}
+			cfb.Root.AddStorage("_VBA_PROJECT_CUR").AddStorage("VBA").AddStream("dir", dir)
+		}
+		buf := &bytes.Buffer{}
+		if _, err := cfb.WriteTo(buf); err != nil {
+			t.Fatal(err)
+		}
+		wb, err := OpenReader(bytes.NewReader(buf.Bytes()), "utf-8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := wb.VBAProject()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (p != nil) != withVBA {
+			t.Errorf("with VBA %t: got project %+v", withVBA, p)
+		}
+	}
+}
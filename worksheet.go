@@ -16,6 +16,16 @@ const (
 	WorkSheetVeryHidden TWorkSheetVisibility = 2
 )
 
+// SheetType is the kind of a sheet.
+type SheetType byte
+
+const (
+	SheetWorksheet SheetType = 0
+	SheetMacro     SheetType = 1 // Excel 4.0 macro sheet.
+	SheetChart     SheetType = 2
+	SheetVBModule  SheetType = 6
+)
+
 type boundsheet struct {
 	Filepos uint32
 	Visible byte
@@ -30,11 +40,41 @@ type WorkSheet struct {
 	Name       string
 	Selected   bool
 	Visibility TWorkSheetVisibility
+	Type       SheetType
 	rows       map[uint16]*Row
 	//NOTICE: this is the max row number of the sheet, so it should be count -1
 	MaxRow      uint16
+	MergedCells []CellRange
 	parsed      bool
-	rightToLeft bool
+	RightToLeft bool
+	View        SheetView
+	PageSetup   PageSetup
+	selections  []selection
+
+	DataValidations    []DataValidation
+	ConditionalFormats []ConditionalFormat
+	// AutoFilter is nil if the sheet has no autofilter.
+	AutoFilter *AutoFilter
+	Pictures   []Picture
+	// Charts are the charts embedded in the sheet, or the chart of a
+	// chart sheet.
+	Charts []Chart
+	// EmbeddedObjects are the OLE objects embedded in the sheet.
+	EmbeddedObjects []EmbeddedObject
+	// Macros holds the formulas of an Excel 4.0 macro sheet.
+	Macros     []MacroCell
+	Protection SheetProtection
+
+	drawing []byte
+	shapes  []shape
+	objs    []object
+
+	colInfos      []colInfo
+	defColWidth   uint16
+	standardWidth uint16
+
+	defRowHeight uint16
+	defRowFlags  uint16
 }
 
 func (w *WorkSheet) Row(i int) *Row {
@@ -45,16 +85,65 @@ func (w *WorkSheet) Row(i int) *Row {
 	return row
 }
 
+// DefaultRowHeight is the height in points of rows without a ROW record.
+func (w *WorkSheet) DefaultRowHeight() float64 {
+	return float64(w.defRowHeight&0x7FFF) / 20
+}
+
 func (w *WorkSheet) parse(buf io.ReadSeeker) error {
 	w.rows = make(map[uint16]*Row)
+	w.MergedCells = nil
+	w.colInfos = nil
+	w.defColWidth = 8
+	w.standardWidth = 0
+	w.defRowHeight = 0xFF
+	w.defRowFlags = 0
+	w.View = defaultSheetView()
+	w.PageSetup = defaultPageSetup()
+	w.DataValidations = nil
+	w.ConditionalFormats = nil
+	w.AutoFilter = nil
+	w.Pictures = nil
+	w.shapes = nil
+	w.objs = nil
+	w.Charts = nil
+	w.EmbeddedObjects = nil
+	w.Macros = nil
+	w.Protection = defaultSheetProtection()
+	w.selections = nil
 	b := new(bof)
 	var colPre interface{}
 	var err error
-	for {
+	for started := false; ; started = true {
 		err = binary.Read(buf, binary.LittleEndian, b)
 		if err != nil {
 			return err
 		}
+		if b.ID == 0x809 { // BOF
+			bts := make([]byte, b.Size)
+			if _, err = io.ReadFull(buf, bts); err != nil {
+				return err
+			}
+			var h biffHeader
+			binary.Read(bytes.NewReader(bts), binary.LittleEndian, &h)
+			if h.Type != 0x20 {
+				continue
+			}
+			// A chart sheet, or a chart embedded after its OBJ record
+			// with records up to its own EOF.
+			c, err := w.parseChart(buf)
+			if err != nil {
+				return err
+			}
+			w.Charts = append(w.Charts, c)
+			if !started {
+				break
+			}
+			if n := len(w.objs); n > 0 {
+				w.objs[n-1].chart = len(w.Charts)
+			}
+			continue
+		}
 		colPre, err = w.parseBof(buf, b, colPre)
 		if err != nil {
 			return err
@@ -63,6 +152,12 @@ func (w *WorkSheet) parse(buf io.ReadSeeker) error {
 			break
 		}
 	}
+	w.View.setSelection(w.selections)
+	w.setFilterRange()
+	w.parseDrawing()
+	w.linkObjects()
+	w.objs = nil
+	w.selections = nil
 	w.parsed = true
 	return nil
 }
@@ -73,16 +168,123 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, colPre interface{}) (int
 	binary.Read(buf, binary.LittleEndian, bts)
 	buf = bytes.NewReader(bts)
 	switch b.ID {
-	// case 0x0E5: //MERGEDCELLS
-	// ws.mergedCells(buf)
+	case 0x0E5: //MERGEDCELLS
+		var count uint16
+		binary.Read(buf, binary.LittleEndian, &count)
+		for i := uint16(0); i < count; i++ {
+			var cr CellRange
+			if err := binary.Read(buf, binary.LittleEndian, &cr); err != nil {
+				break
+			}
+			w.MergedCells = append(w.MergedCells, cr)
+		}
+	case 0x07D: // COLINFO
+		// Some writers omit the two trailing unused bytes.
+		if len(bts) >= 10 {
+			var c colInfo
+			binary.Read(bytes.NewReader(append(bts, 0, 0)), binary.LittleEndian, &c)
+			w.colInfos = append(w.colInfos, c)
+		}
+	case 0x055: // DEFCOLWIDTH
+		binary.Read(buf, binary.LittleEndian, &w.defColWidth)
+	case 0x099: // STANDARDWIDTH
+		binary.Read(buf, binary.LittleEndian, &w.standardWidth)
+	case 0x225: // DEFAULTROWHEIGHT
+		binary.Read(buf, binary.LittleEndian, &w.defRowFlags)
+		binary.Read(buf, binary.LittleEndian, &w.defRowHeight)
 	case 0x23E: // WINDOW2
-		var sheetOptions, firstVisibleRow, firstVisibleColumn uint16
-		binary.Read(buf, binary.LittleEndian, &sheetOptions)
-		binary.Read(buf, binary.LittleEndian, &firstVisibleRow)    // not valuable
-		binary.Read(buf, binary.LittleEndian, &firstVisibleColumn) // not valuable
-		//buf.Seek(int64(b.Size)-2*3, 1)
-		w.rightToLeft = (sheetOptions & 0x40) != 0
+		var sheetOptions uint16
+		binary.Read(bytes.NewReader(bts), binary.LittleEndian, &sheetOptions)
+		w.View.parseWindow2(buf, w.wb.Is5ver)
+		w.RightToLeft = w.View.RightToLeft
 		w.Selected = (sheetOptions & 0x400) != 0
+	case 0x041: // PANE
+		w.View.parsePane(buf)
+	case 0x0A0: // SCL
+		w.View.parseScl(buf)
+	case 0x01D: // SELECTION
+		w.selections = append(w.selections, parseSelection(buf))
+	case 0x862: // SHEETEXT
+		w.View.parseSheetExt(bts)
+	case 0x0A1: // SETUP
+		w.PageSetup.parseSetup(buf)
+	case 0x014: // HEADER
+		w.PageSetup.Header = w.parseHeaderFooter(bts)
+	case 0x015: // FOOTER
+		w.PageSetup.Footer = w.parseHeaderFooter(bts)
+	case 0x026: // LEFTMARGIN
+		binary.Read(buf, binary.LittleEndian, &w.PageSetup.LeftMargin)
+	case 0x027: // RIGHTMARGIN
+		binary.Read(buf, binary.LittleEndian, &w.PageSetup.RightMargin)
+	case 0x028: // TOPMARGIN
+		binary.Read(buf, binary.LittleEndian, &w.PageSetup.TopMargin)
+	case 0x029: // BOTTOMMARGIN
+		binary.Read(buf, binary.LittleEndian, &w.PageSetup.BottomMargin)
+	case 0x083: // HCENTER
+		var v uint16
+		binary.Read(buf, binary.LittleEndian, &v)
+		w.PageSetup.HCenter = v != 0
+	case 0x084: // VCENTER
+		var v uint16
+		binary.Read(buf, binary.LittleEndian, &v)
+		w.PageSetup.VCenter = v != 0
+	case 0x02A: // PRINTHEADERS
+		var v uint16
+		binary.Read(buf, binary.LittleEndian, &v)
+		w.PageSetup.PrintHeaders = v != 0
+	case 0x02B: // PRINTGRIDLINES
+		var v uint16
+		binary.Read(buf, binary.LittleEndian, &v)
+		w.PageSetup.PrintGridlines = v != 0
+	case 0x01B: // HORIZONTALPAGEBREAKS
+		w.PageSetup.RowBreaks = parsePageBreaks(buf, w.wb.Is5ver)
+	case 0x01A: // VERTICALPAGEBREAKS
+		w.PageSetup.ColBreaks = parsePageBreaks(buf, w.wb.Is5ver)
+	case 0x012: // PROTECT
+		w.Protection.Protected = len(bts) >= 2 && bts[0] != 0
+	case 0x013: // PASSWORD
+		if len(bts) >= 2 {
+			w.Protection.Password = binary.LittleEndian.Uint16(bts)
+		}
+	case 0x063: // OBJPROTECT
+		w.Protection.Objects = len(bts) >= 2 && bts[0] != 0
+	case 0x0DD: // SCENPROTECT
+		w.Protection.Scenarios = len(bts) >= 2 && bts[0] != 0
+	case 0x867: // FEATHEADR
+		w.Protection.parseFeatHeader(bts)
+	case 0x081: // WSBOOL
+		var v uint16
+		binary.Read(buf, binary.LittleEndian, &v)
+		w.PageSetup.FitToPage = v&0x100 != 0
+	case 0x1B2: // DVAL
+		// The DV records of the sheet follow.
+		w.DataValidations = nil
+	case 0x1BE: // DV
+		w.DataValidations = append(w.DataValidations, w.parseDV(bts))
+	case 0x1B0: // CONDFMT
+		w.ConditionalFormats = append(w.ConditionalFormats, parseCondFmt(bts))
+	case 0x1B1: // CF
+		if n := len(w.ConditionalFormats); n > 0 {
+			cf := &w.ConditionalFormats[n-1]
+			cf.Rules = append(cf.Rules, w.parseCF(bts, cf.base()))
+		}
+	case 0x09B: // FILTERMODE
+		if w.AutoFilter == nil {
+			w.AutoFilter = new(AutoFilter)
+		}
+		w.AutoFilter.Active = true
+	case 0x09D: // AUTOFILTERINFO
+		if w.AutoFilter == nil {
+			w.AutoFilter = new(AutoFilter)
+		}
+	case 0x09E: // AUTOFILTER
+		if w.AutoFilter != nil {
+			w.AutoFilter.Columns = append(w.AutoFilter.Columns, w.parseAutoFilter(bts))
+		}
+	case 0x0EC: // MSODRAWING
+		w.drawing = append(w.drawing, bts...)
+	case 0x05D: // OBJ
+		w.objs = append(w.objs, w.parseObj(bts))
 	case 0x208: //ROW
 		r := new(rowInfo)
 		binary.Read(buf, binary.LittleEndian, r)
@@ -115,14 +317,22 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, colPre interface{}) (int
 		binary.Read(buf, binary.LittleEndian, &c.Header)
 		c.Bts = make([]byte, b.Size-20)
 		binary.Read(buf, binary.LittleEndian, &c.Bts)
+		if w.Type == SheetMacro {
+			w.Macros = append(w.Macros, w.macroCell(c))
+		}
 		col = c
 	case 0x207: //STRING = FORMULA-VALUE is expected right after FORMULA
 		ch, ok := colPre.(*FormulaCol)
 		if !ok {
 			return nil, fmt.Errorf("Expected formula token, got %T", colPre)
 		}
+		// The string result replaces the formula cell.
+		if r, ok := w.rows[ch.Row()]; ok {
+			delete(r.cols, ch.FirstCol())
+		}
 		c := new(FormulaStringCol)
 		c.Col = ch.Header.Col
+		c.Xf = ch.Header.IndexXf
 		var cStringLen uint16
 		binary.Read(buf, binary.LittleEndian, &cStringLen)
 		str, err := w.wb.getString(buf, cStringLen)
@@ -146,6 +356,9 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, colPre interface{}) (int
 	case 0x201: //BLANK
 		col = new(BlankCol)
 		binary.Read(buf, binary.LittleEndian, col)
+	case 0x205: //BOOLERR
+		col = new(BoolErrCol)
+		binary.Read(buf, binary.LittleEndian, col)
 	case 0x1b8: //HYPERLINK
 		var hy HyperLink
 		binary.Read(buf, binary.LittleEndian, &hy.CellRange)
@@ -196,8 +409,6 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, colPre interface{}) (int
 		for i := hy.CellRange.FirstRow(); i <= hy.CellRange.LastRow(); i++ {
 			w.addContent(i, &hy)
 		}
-	case 0x809:
-		buf.Seek(int64(b.Size), 1)
 	case 0xa:
 	default:
 		buf.Seek(int64(b.Size), 1)
@@ -212,9 +423,18 @@ func (w *WorkSheet) addContent(rowNum uint16, ch contentHandler) {
 	var row *Row
 	var ok bool
 	if row, ok = w.rows[rowNum]; !ok {
-		row = w.addRow(&rowInfo{
-			Index: rowNum,
-		})
+		// Rows without a ROW record use the sheet defaults.
+		info := &rowInfo{
+			Index:  rowNum,
+			Height: w.defRowHeight,
+		}
+		if w.defRowFlags&0x1 != 0 {
+			info.Flags |= rowCustom
+		}
+		if w.defRowFlags&0x2 != 0 {
+			info.Flags |= rowHidden
+		}
+		row = w.addRow(info)
 	}
 	if row.info.Lcell < ch.LastCol() {
 		row.info.Lcell = ch.LastCol()
@@ -0,0 +1,159 @@
+package xls
+
+import (
+	"bytes"
+	"testing"
+)
+
+// sheetFromRecords parses a worksheet substream made of recs followed by
+// an EOF record.
+func sheetFromRecords(t *testing.T, wb *WorkBook, recs ...[]byte) *WorkSheet {
+	t.Helper()
+	if wb == nil {
+		wb = &WorkBook{Formats: make(map[uint16]*Format)}
+	}
+	var stream []byte
+	for _, r := range recs {
+		stream = append(stream, r...)
+	}
+	stream = append(stream, biffRecord(0x0A, nil)...)
+	s := &WorkSheet{wb: wb}
+	if err := s.parse(bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestColumns(t *testing.T) {
+	s := sheetFromRecords(t, nil,
+		biffRecord(0x055, le16(10)),
+		biffRecord(0x07D, le16(1, 3, 20*256, 17, 0x0002, 0)),
+		biffRecord(0x07D, le16(4, 4, 2560, 15, 0x1201)),
+	)
+	if got := s.Column(2); got != (Column{Width: 20, Pixels: 140, CustomWidth: true, XF: 17}) {
+		t.Fatalf("got %+v", got)
+	}
+	if got := s.Column(4); got != (Column{Width: 10, Pixels: 70, Hidden: true, OutlineLevel: 2, Collapsed: true, XF: 15}) {
+		t.Fatalf("got %+v", got)
+	}
+	if got := s.Column(0); got.Pixels != 75 || got.XF != 15 || got.Hidden {
+		t.Fatalf("got default %+v", got)
+	}
+
+	s = sheetFromRecords(t, nil, biffRecord(0x099, le16(9*256)))
+	if got := s.Column(200); got.Width != 9 || got.Pixels != 63 {
+		t.Fatalf("got standard width %+v", got)
+	}
+}
+
+func TestColumnsWriter(t *testing.T) {
+	w := NewWriter()
+	s := w.AddSheet("Sheet1")
+	s.SetString(0, 0, "x", 0)
+	s.SetColumnWidth(1, 2, 12.5)
+	buf := &bytes.Buffer{}
+	if err := w.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := OpenReader(bytes.NewReader(buf.Bytes()), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sheet.Column(2); got.Width != 12.5 || got.Pixels != 88 {
+		t.Fatalf("got %+v", got)
+	}
+	if got := sheet.Column(0); got.Width != float64(61)/7 || got.Pixels != 61 {
+		t.Fatalf("got default %+v", got)
+	}
+}
+
+func rowRecord(index, height uint16, flags uint32) []byte {
+	return biffRecord(0x208, append(le16(index, 0, 1, height, 0, 0), le32(flags)...))
+}
+
+func TestRows(t *testing.T) {
+	s := sheetFromRecords(t, nil,
+		biffRecord(0x225, le16(0x1, 300)),
+		rowRecord(2, 400, 0x2|rowCollapsed|rowHidden|rowCustom|rowFormatted|0x100|20<<16),
+		rowRecord(3, 255, 0x100),
+		biffRecord(0x201, le16(2, 0, 15)),
+		biffRecord(0x201, le16(5, 0, 15)),
+	)
+	if got := s.DefaultRowHeight(); got != 15 {
+		t.Fatalf("got default height %v", got)
+	}
+	r := s.Row(2)
+	if r.Height() != 20 || !r.CustomHeight() || !r.Hidden() || r.OutlineLevel() != 2 || !r.Collapsed() {
+		t.Fatalf("got row 2 %v %t %t %d %t", r.Height(), r.CustomHeight(), r.Hidden(), r.OutlineLevel(), r.Collapsed())
+	}
+	if xf, ok := r.XF(); !ok || xf != 20 {
+		t.Fatalf("got row 2 xf %d %t", xf, ok)
+	}
+	r = s.Row(3)
+	if r.Height() != 12.75 || r.CustomHeight() || r.Hidden() || r.OutlineLevel() != 0 {
+		t.Fatalf("got row 3 %v", r.Height())
+	}
+	if _, ok := r.XF(); ok {
+		t.Fatal("row 3 has no format")
+	}
+	// Row 5 has a cell but no ROW record.
+	r = s.Row(5)
+	if r.Height() != 15 || !r.CustomHeight() || r.Hidden() {
+		t.Fatalf("got row 5 %v %t", r.Height(), r.CustomHeight())
+	}
+}
+
+func TestSheetView(t *testing.T) {
+	s := sheetFromRecords(t, nil)
+	if v := s.View; !v.ShowGridlines || v.Zoom != 100 || v.TabColor != -1 || v.Frozen {
+		t.Errorf("default view = %+v", v)
+	}
+
+	// Active cell D4 and one range D4:D4 in the bottom right pane.
+	sel := append([]byte{PaneBottomRight}, le16(3, 4, 0, 1, 3, 3)...)
+	sel = append(sel, 4, 4)
+	ext := make([]byte, 20)
+	ext[16] = 10
+	s = sheetFromRecords(t, nil,
+		biffRecord(0x23E, le16(0x0EFE, 0, 0, 12, 0, 0, 75, 0, 0)),
+		biffRecord(0x0A0, le16(3, 2)),
+		biffRecord(0x041, append(le16(2, 1, 1, 2), PaneBottomRight, 0)),
+		biffRecord(0x01D, append([]byte{PaneTopLeft}, le16(0, 0, 0, 1, 0, 0)...)),
+		biffRecord(0x01D, sel),
+		biffRecord(0x862, ext),
+	)
+	v := s.View
+	if v.ShowFormulas || !v.ShowGridlines || !v.ShowHeaders || !v.ShowZeros || !v.ShowOutline {
+		t.Errorf("visibility = %+v", v)
+	}
+	if !v.RightToLeft || !s.RightToLeft || !s.Selected || !v.PageBreakPreview {
+		t.Errorf("flags = %+v, selected %v", v, s.Selected)
+	}
+	if v.GridColor != ColorWindowText {
+		t.Errorf("grid color = %d", v.GridColor)
+	}
+	if v.Zoom != 150 {
+		t.Errorf("zoom = %d, want 150", v.Zoom)
+	}
+	if !v.Frozen || v.FrozenRows != 1 || v.FrozenCols != 2 || v.PaneFirstRow != 1 || v.PaneFirstCol != 2 {
+		t.Errorf("panes = %+v", v)
+	}
+	if v.ActivePane != PaneBottomRight || v.ActiveRow != 3 || v.ActiveCol != 4 {
+		t.Errorf("active cell = %d %d:%d", v.ActivePane, v.ActiveRow, v.ActiveCol)
+	}
+	if len(v.Selection) != 1 || v.Selection[0].FirstRow() != 3 || v.Selection[0].FirstCol() != 4 {
+		t.Errorf("selection = %+v", v.Selection)
+	}
+	if v.TabColor != 10 {
+		t.Errorf("tab color = %d, want 10", v.TabColor)
+	}
+
+	ext[16] = 0x7F
+	if s = sheetFromRecords(t, nil, biffRecord(0x862, ext)); s.View.TabColor != -1 {
+		t.Errorf("tab color = %d, want -1", s.View.TabColor)
+	}
+}
@@ -0,0 +1,628 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+// Maximum size of a BIFF8 record body.
+const maxRecordSize = 8224
+
+// WriteFont is a font added to a WorkBookWriter.
+type WriteFont struct {
+	Name      string // Defaults to "Arial".
+	Height    uint16 // In twips (1/20 of a point), defaults to 200.
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Strikeout bool
+	Color     uint16 // Palette index, zero is the automatic color.
+}
+
+// WriteStyle is a cell format (XF) added to a WorkBookWriter.
+type WriteStyle struct {
+	Font      int    // Index returned by AddFont, zero is the default font.
+	Format    string // Number format such as "0.00" or "yyyy-mm-dd", empty is General.
+	HAlign    HAlign
+	VAlign    VAlign // The zero value is bottom aligned like Excel's default, not VAlignTop.
+	Wrap      bool
+	FillColor uint16 // Palette index of a solid fill, zero for no fill.
+}
+
+// WorkBookWriter creates a BIFF8 XLS file.
+type WorkBookWriter struct {
+	fonts     []WriteFont
+	formats   []string
+	styles    []WriteStyle
+	sheets    []*SheetWriter
+	sst       []string
+	sstIndex  map[string]uint32
+	sstTotal  uint32
+	dateStyle uint16
+}
+
+// NewWriter returns an empty workbook writer.
+func NewWriter() *WorkBookWriter {
+	return &WorkBookWriter{
+		sstIndex: make(map[string]uint32),
+	}
+}
+
+// AddFont adds a font and returns its index for use in WriteStyle.
+func (w *WorkBookWriter) AddFont(f WriteFont) int {
+	w.fonts = append(w.fonts, f)
+	// Font index 4 does not exist in BIFF files.
+	return len(w.fonts) + 4
+}
+
+// AddStyle adds a cell format and returns its XF index for use with the
+// SheetWriter Set methods.
+func (w *WorkBookWriter) AddStyle(s WriteStyle) uint16 {
+	w.styles = append(w.styles, s)
+	return uint16(defaultXF + len(w.styles))
+}
+
+// formatIndex returns the format index of a number format, adding it if needed.
+func (w *WorkBookWriter) formatIndex(format string) uint16 {
+	if format == "" || format == "General" {
+		return 0
+	}
+	for i, f := range w.formats {
+		if f == format {
+			return uint16(164 + i)
+		}
+	}
+	w.formats = append(w.formats, format)
+	return uint16(164 + len(w.formats) - 1)
+}
+
+// AddSheet adds a worksheet. An invalid or duplicate name is reported by
+// Write.
+func (w *WorkBookWriter) AddSheet(name string) *SheetWriter {
+	s := &SheetWriter{
+		wb:   w,
+		name: name,
+		rows: make(map[int]map[int]*wcell),
+	}
+	s.err = checkSheetName(name)
+	for _, o := range w.sheets {
+		if s.err == nil && strings.EqualFold(o.name, name) {
+			s.err = fmt.Errorf("duplicate sheet name")
+		}
+	}
+	w.sheets = append(w.sheets, s)
+	return s
+}
+
+// checkSheetName checks the rules Excel has for sheet names.
+func checkSheetName(name string) error {
+	if n := len(utf16.Encode([]rune(name))); n == 0 || n > 31 {
+		return fmt.Errorf("sheet name must have 1 to 31 characters")
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) {
+		return fmt.Errorf(`sheet name must not contain any of []:*?/\`)
+	}
+	if name[0] == '\'' || name[len(name)-1] == '\'' {
+		return fmt.Errorf("sheet name must not start or end with an apostrophe")
+	}
+	return nil
+}
+
+func (w *WorkBookWriter) addString(v string) uint32 {
+	w.sstTotal++
+	if i, ok := w.sstIndex[v]; ok {
+		return i
+	}
+	i := uint32(len(w.sst))
+	w.sst = append(w.sst, v)
+	w.sstIndex[v] = i
+	return i
+}
+
+const (
+	wcellBlank = iota
+	wcellNumber
+	wcellString
+	wcellBool
+)
+
+type wcell struct {
+	kind byte
+	xf   uint16
+	num  float64
+	sst  uint32
+	b    bool
+}
+
+type wcolInfo struct {
+	first, last uint16
+	width       uint16
+}
+
+// SheetWriter adds cells to a worksheet of a WorkBookWriter.
+// An xf of zero uses the default cell format.
+type SheetWriter struct {
+	wb     *WorkBookWriter
+	name   string
+	rows   map[int]map[int]*wcell
+	cols   []wcolInfo
+	merged []CellRange
+	err    error
+}
+
+func (s *SheetWriter) set(row, col int, c *wcell) {
+	if row < 0 || row > 0xFFFF || col < 0 || col > 0xFF {
+		if s.err == nil {
+			s.err = fmt.Errorf("cell %d/%d out of range", row, col)
+		}
+		return
+	}
+	if c.xf == 0 {
+		c.xf = defaultXF
+	}
+	r := s.rows[row]
+	if r == nil {
+		r = make(map[int]*wcell)
+		s.rows[row] = r
+	}
+	r[col] = c
+}
+
+// SetString sets a text cell.
+func (s *SheetWriter) SetString(row, col int, v string, xf uint16) {
+	s.set(row, col, &wcell{kind: wcellString, xf: xf, sst: s.wb.addString(v)})
+}
+
+// SetNumber sets a numeric cell.
+func (s *SheetWriter) SetNumber(row, col int, v float64, xf uint16) {
+	s.set(row, col, &wcell{kind: wcellNumber, xf: xf, num: v})
+}
+
+// SetDate sets a date cell. An xf of zero uses a "yyyy-mm-dd" format.
+func (s *SheetWriter) SetDate(row, col int, t time.Time, xf uint16) {
+	if xf == 0 {
+		if s.wb.dateStyle == 0 {
+			s.wb.dateStyle = s.wb.AddStyle(WriteStyle{Format: "yyyy-mm-dd", VAlign: VAlignBottom})
+		}
+		xf = s.wb.dateStyle
+	}
+	s.SetNumber(row, col, excelTimeFromTime(t), xf)
+}
+
+// SetBool sets a boolean cell.
+func (s *SheetWriter) SetBool(row, col int, v bool, xf uint16) {
+	s.set(row, col, &wcell{kind: wcellBool, xf: xf, b: v})
+}
+
+// SetBlank sets an empty cell that only carries a format.
+func (s *SheetWriter) SetBlank(row, col int, xf uint16) {
+	s.set(row, col, &wcell{kind: wcellBlank, xf: xf})
+}
+
+// SetColumnWidth sets the width of columns first to last in characters.
+func (s *SheetWriter) SetColumnWidth(first, last int, width float64) {
+	if first < 0 || last < first || last > 0xFF || width < 0 || width > 255 {
+		if s.err == nil {
+			s.err = fmt.Errorf("invalid column width %d-%d: %g", first, last, width)
+		}
+		return
+	}
+	s.cols = append(s.cols, wcolInfo{first: uint16(first), last: uint16(last), width: uint16(width * 256)})
+}
+
+// Merge merges the cells in the given range.
+func (s *SheetWriter) Merge(firstRow, firstCol, lastRow, lastCol int) {
+	if firstRow < 0 || lastRow < firstRow || lastRow > 0xFFFF || firstCol < 0 || lastCol < firstCol || lastCol > 0xFF {
+		if s.err == nil {
+			s.err = fmt.Errorf("invalid merge range %d/%d-%d/%d", firstRow, firstCol, lastRow, lastCol)
+		}
+		return
+	}
+	s.merged = append(s.merged, CellRange{
+		FirstRowB: uint16(firstRow),
+		LastRowB:  uint16(lastRow),
+		FristColB: uint16(firstCol),
+		LastColB:  uint16(lastCol),
+	})
+}
+
+// excelTimeFromTime is the inverse of timeFromExcelTime for the 1900 date system.
+func excelTimeFromTime(t time.Time) float64 {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	days := math.Round(day.Sub(base).Hours() / 24)
+	if days < 61 {
+		// Excel counts the non-existent 1900-02-29.
+		days--
+	}
+	h, min, sec := t.Clock()
+	frac := (float64(h)*3600 + float64(min)*60 + float64(sec) + float64(t.Nanosecond())/1e9) / 86400
+	return days + frac
+}
+
+// biffWriter writes BIFF records.
+type biffWriter struct {
+	bytes.Buffer
+	err error
+}
+
+// record writes a record with the fields encoded little endian.
+func (b *biffWriter) record(id uint16, fields ...interface{}) {
+	var body bytes.Buffer
+	for _, f := range fields {
+		binary.Write(&body, binary.LittleEndian, f)
+	}
+	b.raw(id, body.Bytes())
+}
+
+func (b *biffWriter) raw(id uint16, body []byte) {
+	binary.Write(b, binary.LittleEndian, bof{ID: id, Size: uint16(len(body))})
+	b.Write(body)
+}
+
+// compressible reports if all code units fit in a byte.
+func compressible(u []uint16) bool {
+	for _, c := range u {
+		if c > 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+// xlString encodes a string with a length field of cchSize bytes, a flag
+// byte and the characters, compressed when possible. It fails if the length
+// does not fit the length field.
+func xlString(s string, cchSize int) ([]byte, error) {
+	u := utf16.Encode([]rune(s))
+	var b bytes.Buffer
+	if cchSize == 1 && len(u) > 0xFF || len(u) > 0xFFFF {
+		return nil, fmt.Errorf("string of %d characters is too long", len(u))
+	}
+	if cchSize == 1 {
+		b.WriteByte(byte(len(u)))
+	} else {
+		binary.Write(&b, binary.LittleEndian, uint16(len(u)))
+	}
+	if compressible(u) {
+		b.WriteByte(0)
+		for _, c := range u {
+			b.WriteByte(byte(c))
+		}
+	} else {
+		b.WriteByte(1)
+		binary.Write(&b, binary.LittleEndian, u)
+	}
+	return b.Bytes(), nil
+}
+
+// str encodes a string as xlString does, keeping the first error for
+// workbookStream to report.
+func (b *biffWriter) str(s string, cchSize int) []byte {
+	v, err := xlString(s, cchSize)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	return v
+}
+
+func writeBOF(b *biffWriter, typ uint16) {
+	b.record(0x809, biffHeader{
+		Ver:    0x600,
+		Type:   typ,
+		IDMake: 0x0DBB,
+		Year:   0x07CC,
+		Flags:  0x000100C1,
+		MinVer: 0x00000406,
+	})
+}
+
+func (w *WorkBookWriter) writeFont(b *biffWriter, f WriteFont) {
+	if f.Name == "" {
+		f.Name = "Arial"
+	}
+	if f.Height == 0 {
+		f.Height = 200
+	}
+	if f.Color == 0 {
+		f.Color = 0x7FFF
+	}
+	var flags, weight uint16 = 0, 400
+	var underline byte
+	if f.Italic {
+		flags |= 0x02
+	}
+	if f.Strikeout {
+		flags |= 0x08
+	}
+	if f.Bold {
+		weight = 700
+	}
+	if f.Underline {
+		underline = 1
+	}
+	b.record(0x031, f.Height, flags, f.Color, weight, uint16(0), underline, byte(0), byte(0), byte(0), b.str(f.Name, 1))
+}
+
+// xf8 field values of the default formats.
+const (
+	xfStyle     = 0xFFF5 // Locked style XF without a parent.
+	xfCell      = 0x0001 // Locked cell XF with style 0 as parent.
+	xfAlign     = 0x20   // General, bottom aligned.
+	xfFill      = 0x20C0 // Default pattern colors.
+	xfAllAttrib = 0xFC
+)
+
+func (w *WorkBookWriter) writeXFs(b *biffWriter) {
+	fonts := []uint16{0, 1, 1, 2, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	for i := 0; i < styleXFCount; i++ {
+		var used byte = 0xF4
+		if i == 0 {
+			used = 0
+		}
+		b.record(0x0E0, xf8{Font: fonts[i], Type: xfStyle, Align: xfAlign, Usedattr: used, Groundcolor: xfFill})
+	}
+	b.record(0x0E0, xf8{Type: xfCell, Align: xfAlign, Groundcolor: xfFill})
+	for _, s := range w.styles {
+		valign := s.VAlign
+		if valign == 0 {
+			valign = VAlignBottom
+		}
+		x := xf8{
+			Font:        uint16(s.Font),
+			Format:      w.formatIndex(s.Format),
+			Type:        xfCell,
+			Align:       byte(s.HAlign&0x7) | byte(valign&0x7)<<4,
+			Usedattr:    xfAllAttrib,
+			Groundcolor: xfFill,
+		}
+		if s.Wrap {
+			x.Align |= 0x08
+		}
+		if s.FillColor != 0 {
+			x.Linecolor = 1 << 26 // Solid pattern.
+			x.Groundcolor = s.FillColor&0x7F | 0x41<<7
+		}
+		b.record(0x0E0, x)
+	}
+}
+
+// writeSST writes the shared strings, splitting them over CONTINUE records,
+// followed by the EXTSST index.
+func (w *WorkBookWriter) writeSST(b *biffWriter) {
+	bucket := uint16(8)
+	if n := uint16((len(w.sst) + 127) / 128); n > bucket {
+		bucket = n
+	}
+	type sstinf struct {
+		Pos    uint32
+		Offset uint16
+		_      uint16
+	}
+	var index []sstinf
+
+	rec := &bytes.Buffer{}
+	binary.Write(rec, binary.LittleEndian, []uint32{w.sstTotal, uint32(len(w.sst))})
+	id := uint16(0xFC)
+	flush := func() {
+		b.raw(id, rec.Bytes())
+		rec.Reset()
+		id = 0x3C
+	}
+	for i, s := range w.sst {
+		u := utf16.Encode([]rune(s))
+		size := 2
+		var flag byte = 1
+		if compressible(u) {
+			size, flag = 1, 0
+		}
+		if rec.Len()+3+size > maxRecordSize {
+			flush()
+		}
+		if i%int(bucket) == 0 {
+			index = append(index, sstinf{Pos: uint32(b.Len() + 4 + rec.Len()), Offset: uint16(4 + rec.Len())})
+		}
+		binary.Write(rec, binary.LittleEndian, uint16(len(u)))
+		rec.WriteByte(flag)
+		for len(u) > 0 {
+			n := (maxRecordSize - rec.Len()) / size
+			if n == 0 {
+				flush()
+				// Continued characters restart with the option flags.
+				rec.WriteByte(flag)
+				continue
+			}
+			if n > len(u) {
+				n = len(u)
+			}
+			for _, c := range u[:n] {
+				if size == 1 {
+					rec.WriteByte(byte(c))
+				} else {
+					binary.Write(rec, binary.LittleEndian, c)
+				}
+			}
+			u = u[n:]
+		}
+	}
+	flush()
+	b.record(0x0FF, bucket, index)
+}
+
+func (w *WorkBookWriter) workbookStream() ([]byte, error) {
+	var sheets [][]byte
+	for _, s := range w.sheets {
+		if s.err != nil {
+			return nil, fmt.Errorf("sheet %q: %w", s.name, s.err)
+		}
+		sheets = append(sheets, s.stream(len(sheets) == 0))
+	}
+	// Resolve formats used by styles before writing FORMAT records.
+	for _, s := range w.styles {
+		w.formatIndex(s.Format)
+	}
+
+	b := &biffWriter{}
+	writeBOF(b, 0x0005)
+	b.record(0x0E1, uint16(1200))                                       // INTERFACEHDR
+	b.record(0x0C1, uint16(0))                                          // MMS
+	b.record(0x0E2)                                                     // INTERFACEEND
+	b.record(0x042, uint16(1200))                                       // CODEPAGE
+	b.record(0x03D, []uint16{0, 0, 0x3000, 0x1E00, 0x38, 0, 0, 1, 600}) // WINDOW1
+	b.record(0x022, uint16(0))                                          // DATEMODE
+	for i := 0; i < 4; i++ {
+		w.writeFont(b, WriteFont{})
+	}
+	for _, f := range w.fonts {
+		w.writeFont(b, f)
+	}
+	for i, f := range w.formats {
+		b.record(0x41E, uint16(164+i), b.str(f, 2))
+	}
+	w.writeXFs(b)
+	b.record(0x293, uint16(0x8000), byte(0), byte(0xFF)) // STYLE: Normal
+
+	var positions []int
+	for _, s := range w.sheets {
+		positions = append(positions, b.Len()+4)
+		b.record(0x085, uint32(0), byte(0), byte(0), b.str(s.name, 1))
+	}
+	w.writeSST(b)
+	b.record(0x00A) // EOF
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	stream := b.Bytes()
+	pos := len(stream)
+	for i, s := range sheets {
+		binary.LittleEndian.PutUint32(stream[positions[i]:], uint32(pos))
+		pos += len(s)
+	}
+	for _, s := range sheets {
+		stream = append(stream, s...)
+	}
+	// Excel will not open a workbook stored in the mini stream.
+	if len(stream) < 4096 {
+		stream = append(stream, make([]byte, 4096-len(stream))...)
+	}
+	return stream, nil
+}
+
+func (s *SheetWriter) stream(first bool) []byte {
+	var rows []int
+	for r := range s.rows {
+		rows = append(rows, r)
+	}
+	sort.Ints(rows)
+
+	var dim struct {
+		FirstRow, LastRow uint32
+		FirstCol, LastCol uint16
+		_                 uint16
+	}
+	if len(rows) > 0 {
+		dim.FirstRow = uint32(rows[0])
+		dim.LastRow = uint32(rows[len(rows)-1]) + 1
+		dim.FirstCol = 0xFF
+		for _, r := range rows {
+			for c := range s.rows[r] {
+				if uint16(c) < dim.FirstCol {
+					dim.FirstCol = uint16(c)
+				}
+				if uint16(c)+1 > dim.LastCol {
+					dim.LastCol = uint16(c) + 1
+				}
+			}
+		}
+	}
+
+	b := &biffWriter{}
+	writeBOF(b, 0x0010)
+	b.record(0x055, uint16(8)) // DEFCOLWIDTH
+	for _, c := range s.cols {
+		b.record(0x07D, c.first, c.last, c.width, uint16(defaultXF), uint16(0), uint16(0)) // COLINFO
+	}
+	b.record(0x200, dim) // DIMENSIONS
+
+	for _, r := range rows {
+		cells := s.rows[r]
+		var cols []int
+		for c := range cells {
+			cols = append(cols, c)
+		}
+		sort.Ints(cols)
+		b.record(0x208, rowInfo{
+			Index:  uint16(r),
+			Fcell:  uint16(cols[0]),
+			Lcell:  uint16(cols[len(cols)-1] + 1),
+			Height: 0xFF,
+			Flags:  0x100,
+		})
+	}
+	for _, r := range rows {
+		cells := s.rows[r]
+		var cols []int
+		for c := range cells {
+			cols = append(cols, c)
+		}
+		sort.Ints(cols)
+		for _, c := range cols {
+			cell := cells[c]
+			at := Col{RowB: uint16(r), FirstColB: uint16(c)}
+			switch cell.kind {
+			case wcellBlank:
+				b.record(0x201, BlankCol{Col: at, Xf: cell.xf})
+			case wcellNumber:
+				b.record(0x203, NumberCol{Col: at, Index: cell.xf, Float: cell.num})
+			case wcellString:
+				b.record(0x0FD, LabelsstCol{Col: at, Xf: cell.xf, Sst: cell.sst})
+			case wcellBool:
+				v := BoolErrCol{Col: at, Xf: cell.xf}
+				if cell.b {
+					v.Val = 1
+				}
+				b.record(0x205, v)
+			}
+		}
+	}
+
+	var options uint16 = 0x00B6
+	if first {
+		options |= 0x0600 // Selected and shown.
+	}
+	b.record(0x23E, options, []uint16{0, 0, 0x40, 0, 0, 0, 0, 0}) // WINDOW2
+
+	for merged := s.merged; len(merged) > 0; {
+		n := len(merged)
+		if n > 1026 {
+			n = 1026
+		}
+		b.record(0x0E5, uint16(n), merged[:n]) // MERGEDCELLS
+		merged = merged[n:]
+	}
+	b.record(0x00A) // EOF
+	return b.Bytes()
+}
+
+// Write the workbook as an XLS file to out.
+func (w *WorkBookWriter) Write(out io.Writer) error {
+	stream, err := w.workbookStream()
+	if err != nil {
+		return err
+	}
+	cfb := ole2.NewWriter()
+	cfb.Root.AddStream("Workbook", stream)
+	_, err = cfb.WriteTo(out)
+	return err
+}
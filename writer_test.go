@@ -0,0 +1,201 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kardianos/xls/ole2"
+)
+
+// biffRecord returns a record with its header.
+func biffRecord(id uint16, body []byte) []byte {
+	rec := make([]byte, 4, 4+len(body))
+	binary.LittleEndian.PutUint16(rec, id)
+	binary.LittleEndian.PutUint16(rec[2:], uint16(len(body)))
+	return append(rec, body...)
+}
+
+// mustXLString encodes a string that fits its length field.
+func mustXLString(s string, cchSize int) []byte {
+	b, err := xlString(s, cchSize)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// insertRecords inserts records after the first BOF of a workbook stream,
+// moving the sheet positions of BOUNDSHEET records. It returns the new
+// stream and the position after the inserted records.
+func insertRecords(stream []byte, recs ...[]byte) ([]byte, int) {
+	bofEnd := 4 + int(binary.LittleEndian.Uint16(stream[2:]))
+	var ins []byte
+	for _, r := range recs {
+		ins = append(ins, r...)
+	}
+	var out []byte
+	out = append(out, stream[:bofEnd]...)
+	out = append(out, ins...)
+	out = append(out, stream[bofEnd:]...)
+	start := bofEnd + len(ins)
+	for pos := start; pos+4 <= len(out); {
+		id := binary.LittleEndian.Uint16(out[pos:])
+		size := int(binary.LittleEndian.Uint16(out[pos+2:]))
+		if id == 0x85 {
+			p := binary.LittleEndian.Uint32(out[pos+4:])
+			binary.LittleEndian.PutUint32(out[pos+4:], p+uint32(len(ins)))
+		}
+		pos += 4 + size
+	}
+	return out, start
+}
+
+// packWorkbook stores a workbook stream in a compound file.
+func packWorkbook(t *testing.T, stream []byte) []byte {
+	t.Helper()
+	cfb := ole2.NewWriter()
+	cfb.Root.AddStream("Workbook", stream)
+	buf := &bytes.Buffer{}
+	if _, err := cfb.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	w := NewWriter()
+	bold := w.AddFont(WriteFont{Name: "Verdana", Height: 240, Bold: true})
+	header := w.AddStyle(WriteStyle{Font: bold, HAlign: HAlignCenter, VAlign: VAlignBottom, FillColor: 22})
+	money := w.AddStyle(WriteStyle{Format: "0.00", VAlign: VAlignBottom})
+	date := time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	s := w.AddSheet("Data")
+	s.SetString(0, 0, "Name", header)
+	s.SetString(0, 1, "Amount", header)
+	s.SetString(0, 2, "Date", header)
+	s.SetString(0, 3, "Paid", header)
+	s.Merge(0, 4, 0, 6)
+	s.SetString(0, 4, "Merged", header)
+	s.SetColumnWidth(0, 0, 20)
+	s.SetColumnWidth(1, 3, 12.5)
+	for i := 1; i <= 2000; i++ {
+		s.SetString(i, 0, fmt.Sprintf("row name %d", i), 0)
+		s.SetNumber(i, 1, float64(i)*1.25, money)
+		s.SetDate(i, 2, date.AddDate(0, 0, i), 0)
+		s.SetBool(i, 3, i%2 == 0, 0)
+		s.SetBlank(i, 4, money)
+	}
+	long := strings.Repeat("long text ", 1500)
+	s.SetString(2001, 0, long, 0)
+	wide := strings.Repeat("日本語テキスト", 1500)
+	s.SetString(2002, 0, wide, 0)
+
+	s2 := w.AddSheet("Второй")
+	s2.SetNumber(3, 2, -42, 0)
+	s2.SetString(3, 3, "Name", 0)
+
+	buf := &bytes.Buffer{}
+	if err := w.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	wb, err := OpenReader(bytes.NewReader(buf.Bytes()), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wb.NumSheets() != 2 {
+		t.Fatalf("got %d sheets", wb.NumSheets())
+	}
+	if len(wb.Fonts) != 5 || wb.Fonts[4].Name != "Verdana" || wb.Fonts[4].Info.Bold != 700 {
+		t.Fatalf("unexpected fonts %+v", wb.Fonts)
+	}
+
+	sheet, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Name != "Data" || !sheet.Selected {
+		t.Fatalf("got sheet %q selected %t", sheet.Name, sheet.Selected)
+	}
+	if sheet.MaxRow != 2002 {
+		t.Fatalf("got max row %d", sheet.MaxRow)
+	}
+	if len(sheet.MergedCells) != 1 || sheet.MergedCells[0].FirstCol() != 4 || sheet.MergedCells[0].LastCol() != 6 {
+		t.Fatalf("got merged cells %+v", sheet.MergedCells)
+	}
+
+	check := func(row, col int, want CellValue) {
+		t.Helper()
+		got := sheet.Row(row).Value(col)
+		if got != want {
+			t.Fatalf("cell %d/%d: got %+v, want %+v", row, col, got, want)
+		}
+	}
+	check(0, 1, CellValue{Text: "Amount"})
+	check(0, 4, CellValue{Text: "Merged"})
+	for i := 1; i <= 2000; i++ {
+		check(i, 0, CellValue{Text: fmt.Sprintf("row name %d", i)})
+		check(i, 1, CellValue{Float: float64(i) * 1.25, Format: "0.00"})
+		check(i, 2, CellValue{Float: excelTimeFromTime(date.AddDate(0, 0, i)), Format: "yyyy-mm-dd"})
+		want := CellValue{Text: "FALSE"}
+		if i%2 == 0 {
+			want = CellValue{Text: "TRUE", Int: 1}
+		}
+		check(i, 3, want)
+		check(i, 4, CellValue{})
+	}
+	check(2001, 0, CellValue{Text: long})
+	check(2002, 0, CellValue{Text: wide})
+	if got := sheet.Row(1).Col(2); got != "2021-03-15" {
+		t.Fatalf("got date text %q", got)
+	}
+	if got := wb.ToDateTime(sheet.Row(1).Value(2).Float); !got.Round(time.Millisecond).Equal(date.AddDate(0, 0, 1)) {
+		t.Fatalf("got date %v", got)
+	}
+
+	sheet2, err := wb.GetSheet(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet2.Name != "Второй" || sheet2.Selected {
+		t.Fatalf("got sheet %q selected %t", sheet2.Name, sheet2.Selected)
+	}
+	if got := sheet2.Row(3).Value(2); got.Float != -42 {
+		t.Fatalf("got %+v", got)
+	}
+	if got := sheet2.Row(3).Col(3); got != "Name" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWriterSheetNames(t *testing.T) {
+	for _, name := range []string{"", strings.Repeat("x", 32), "a/b", "[x]", "what?", "'quoted'", "end'"} {
+		w := NewWriter()
+		w.AddSheet(name)
+		if err := w.Write(&bytes.Buffer{}); err == nil {
+			t.Errorf("%q: expected error", name)
+		}
+	}
+	w := NewWriter()
+	w.AddSheet("Data")
+	w.AddSheet("DATA")
+	if err := w.Write(&bytes.Buffer{}); err == nil {
+		t.Error("expected duplicate name error")
+	}
+	w = NewWriter()
+	w.AddSheet(strings.Repeat("ä", 31))
+	w.AddSheet("It's")
+	if err := w.Write(&bytes.Buffer{}); err != nil {
+		t.Error(err)
+	}
+	w = NewWriter()
+	w.AddFont(WriteFont{Name: strings.Repeat("f", 256)})
+	w.AddSheet("Sheet1")
+	if err := w.Write(&bytes.Buffer{}); err == nil {
+		t.Error("expected font name error")
+	}
+}
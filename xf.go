@@ -1,5 +1,12 @@
 package xls
 
+// Number of style XF records every workbook starts with.
+const styleXFCount = 15
+
+// defaultXF is the XF index of the default cell format, the first cell XF
+// after the style XFs.
+const defaultXF = styleXFCount
+
 type xf5 struct {
 	Font      uint16
 	Format    uint16
@@ -15,6 +22,38 @@ func (x *xf5) formatNo() uint16 {
 	return x.Format
 }
 
+// Style decodes the BIFF5 XF record.
+func (x *xf5) Style() Style {
+	s := Style{
+		Font:   x.Font,
+		Format: x.Format,
+	}
+	s.setType(x.Type)
+	s.HAlign = HAlign(x.Align & 0x7)
+	s.Wrap = x.Align&0x8 != 0
+	s.VAlign = VAlign(x.Align >> 4 & 0x7)
+	switch x.Align >> 8 & 0x3 {
+	case 1:
+		s.Rotation = RotationStacked
+	case 2:
+		s.Rotation = 90
+	case 3:
+		s.Rotation = 180
+	}
+	fill := uint32(x.Color) | uint32(x.Fill)<<16
+	line := uint32(x.Border) | uint32(x.Linestyle)<<16
+	s.Fill = Fill{
+		Pattern:         byte(fill >> 16 & 0x3F),
+		ForegroundColor: uint16(fill & 0x7F),
+		BackgroundColor: uint16(fill >> 7 & 0x7F),
+	}
+	s.Bottom = Border{BorderStyle(fill >> 22 & 0x7), uint16(fill >> 25 & 0x7F)}
+	s.Top = Border{BorderStyle(line & 0x7), uint16(line >> 9 & 0x7F)}
+	s.Left = Border{BorderStyle(line >> 3 & 0x7), uint16(line >> 16 & 0x7F)}
+	s.Right = Border{BorderStyle(line >> 6 & 0x7), uint16(line >> 23 & 0x7F)}
+	return s
+}
+
 type xf8 struct {
 	Font        uint16
 	Format      uint16
@@ -32,6 +71,147 @@ func (x *xf8) formatNo() uint16 {
 	return x.Format
 }
 
+// Style decodes the BIFF8 XF record.
+func (x *xf8) Style() Style {
+	s := Style{
+		Font:   x.Font,
+		Format: x.Format,
+	}
+	s.setType(x.Type)
+	s.HAlign = HAlign(x.Align & 0x7)
+	s.Wrap = x.Align&0x8 != 0
+	s.VAlign = VAlign(x.Align >> 4 & 0x7)
+	s.Rotation = x.Rotation
+	s.Indent = x.Ident & 0xF
+	s.ShrinkToFit = x.Ident&0x10 != 0
+	s.Left = Border{BorderStyle(x.Linestyle & 0xF), uint16(x.Linestyle >> 16 & 0x7F)}
+	s.Right = Border{BorderStyle(x.Linestyle >> 4 & 0xF), uint16(x.Linestyle >> 23 & 0x7F)}
+	s.Top = Border{BorderStyle(x.Linestyle >> 8 & 0xF), uint16(x.Linecolor & 0x7F)}
+	s.Bottom = Border{BorderStyle(x.Linestyle >> 12 & 0xF), uint16(x.Linecolor >> 7 & 0x7F)}
+	s.Diagonal = Border{BorderStyle(x.Linecolor >> 21 & 0xF), uint16(x.Linecolor >> 14 & 0x7F)}
+	s.DiagonalDown = x.Linestyle&(1<<30) != 0
+	s.DiagonalUp = x.Linestyle&(1<<31) != 0
+	s.Fill = Fill{
+		Pattern:         byte(x.Linecolor >> 26 & 0x3F),
+		ForegroundColor: x.Groundcolor & 0x7F,
+		BackgroundColor: x.Groundcolor >> 7 & 0x7F,
+	}
+	return s
+}
+
 type XF interface {
 	formatNo() uint16
+	Style() Style
+}
+
+// HAlign is the horizontal alignment of a cell.
+type HAlign byte
+
+const (
+	HAlignGeneral HAlign = iota
+	HAlignLeft
+	HAlignCenter
+	HAlignRight
+	HAlignFill
+	HAlignJustify
+	HAlignCenterAcross
+	HAlignDistributed
+)
+
+// VAlign is the vertical alignment of a cell.
+type VAlign byte
+
+const (
+	VAlignTop VAlign = iota
+	VAlignCenter
+	VAlignBottom
+	VAlignJustify
+	VAlignDistributed
+)
+
+// RotationStacked is the Style.Rotation of vertically stacked text.
+const RotationStacked = 255
+
+// BorderStyle is the line style of a cell border.
+type BorderStyle byte
+
+const (
+	BorderNone BorderStyle = iota
+	BorderThin
+	BorderMedium
+	BorderDashed
+	BorderDotted
+	BorderThick
+	BorderDouble
+	BorderHair
+	BorderMediumDashed
+	BorderDashDot
+	BorderMediumDashDot
+	BorderDashDotDot
+	BorderMediumDashDotDot
+	BorderSlantDashDot
+)
+
+// Border is one edge of a cell border.
+type Border struct {
+	Style BorderStyle
+	Color uint16 // Palette index.
+}
+
+// Fill is the background pattern of a cell. Pattern 0 is no fill and 1 is
+// a solid fill in the foreground color.
+type Fill struct {
+	Pattern         byte
+	ForegroundColor uint16 // Palette index.
+	BackgroundColor uint16 // Palette index.
+}
+
+// Style is the decoded format of an XF record.
+type Style struct {
+	Font   uint16 // Font record index, index 4 is never used.
+	Format uint16 // Number format index.
+
+	// IsStyle is set for style XFs, cell XFs inherit from the Parent style.
+	IsStyle bool
+	Parent  uint16
+	Locked  bool
+	Hidden  bool
+
+	HAlign      HAlign
+	VAlign      VAlign
+	Wrap        bool
+	ShrinkToFit bool
+	Indent      byte
+	// Rotation is 0 to 90 degrees counterclockwise, 91 to 180 for 1 to 90
+	// degrees clockwise or RotationStacked.
+	Rotation byte
+
+	Left, Right, Top, Bottom Border
+	// Diagonal is drawn top left to bottom right with DiagonalDown and
+	// bottom left to top right with DiagonalUp.
+	Diagonal     Border
+	DiagonalDown bool
+	DiagonalUp   bool
+
+	Fill Fill
+}
+
+func (s *Style) setType(t uint16) {
+	s.Locked = t&0x1 != 0
+	s.Hidden = t&0x2 != 0
+	s.IsStyle = t&0x4 != 0
+	s.Parent = t >> 4
+}
+
+// xfIndexer is implemented by cells that carry an XF index.
+type xfIndexer interface {
+	xfIndex(col uint16) uint16
+}
+
+// Style returns the decoded XF record at index idx.
+func (w *WorkBook) Style(idx int) (Style, bool) {
+	if idx < 0 || idx >= len(w.XF) {
+		return Style{}, false
+	}
+	return w.XF[idx].Style(), true
 }
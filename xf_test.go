@@ -0,0 +1,107 @@
+package xls
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestXF8Style(t *testing.T) {
+	x := &xf8{
+		Font:      5,
+		Format:    164,
+		Type:      0x0012 | 0x2, // Parent 1, hidden.
+		Align:     0x03 | 0x08 | 0x10,
+		Rotation:  45,
+		Ident:     0x12,
+		Linestyle: 0x1 | 0x2<<4 | 0x5<<8 | 0x6<<12 | 8<<16 | 10<<23 | 1<<31,
+		Linecolor: 12 | 13<<7 | 14<<14 | 0x7<<21 | 3<<26,
+		// Foreground 17, background 64 (the system window color).
+		Groundcolor: 17 | 64<<7,
+	}
+	want := Style{
+		Font:        5,
+		Format:      164,
+		Parent:      1,
+		Hidden:      true,
+		HAlign:      HAlignRight,
+		VAlign:      VAlignCenter,
+		Wrap:        true,
+		ShrinkToFit: true,
+		Indent:      2,
+		Rotation:    45,
+		Left:        Border{BorderThin, 8},
+		Right:       Border{BorderMedium, 10},
+		Top:         Border{BorderThick, 12},
+		Bottom:      Border{BorderDouble, 13},
+		Diagonal:    Border{BorderHair, 14},
+		DiagonalUp:  true,
+		Fill:        Fill{Pattern: 3, ForegroundColor: 17, BackgroundColor: 64},
+	}
+	if got := x.Style(); got != want {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestXF5Style(t *testing.T) {
+	x := &xf5{
+		Type:      0xFFF5,
+		Align:     0x02 | 0x20 | 2<<8,
+		Color:     10 | 20<<7,
+		Fill:      1 | 2<<6 | 30<<9,
+		Border:    0x1 | 0x3<<3 | 0x5<<6 | 40<<9,
+		Linestyle: 50 | 60<<7,
+	}
+	want := Style{
+		IsStyle:  true,
+		Locked:   true,
+		Parent:   0xFFF,
+		HAlign:   HAlignCenter,
+		VAlign:   VAlignBottom,
+		Rotation: 90,
+		Fill:     Fill{Pattern: 1, ForegroundColor: 10, BackgroundColor: 20},
+		Bottom:   Border{BorderMedium, 30},
+		Top:      Border{BorderThin, 40},
+		Left:     Border{BorderDashed, 50},
+		Right:    Border{BorderThick, 60},
+	}
+	if got := x.Style(); got != want {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRowStyle(t *testing.T) {
+	w := NewWriter()
+	bold := w.AddFont(WriteFont{Name: "Verdana", Bold: true})
+	header := w.AddStyle(WriteStyle{Font: bold, HAlign: HAlignCenter, Wrap: true, FillColor: 22})
+	s := w.AddSheet("Sheet1")
+	s.SetString(0, 0, "Header", header)
+	s.SetNumber(0, 1, 1, 0)
+	buf := &bytes.Buffer{}
+	if err := w.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := OpenReader(bytes.NewReader(buf.Bytes()), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := wb.GetSheet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, ok := sheet.Row(0).Style(0)
+	if !ok {
+		t.Fatal("missing style")
+	}
+	if st.Font != uint16(bold) || st.HAlign != HAlignCenter || st.VAlign != VAlignBottom || !st.Wrap || !st.Locked {
+		t.Fatalf("got %+v", st)
+	}
+	if st.Fill.Pattern != 1 || st.Fill.ForegroundColor != 22 || st.IsStyle || st.Parent != 0 {
+		t.Fatalf("got %+v", st)
+	}
+	if st, ok := sheet.Row(0).Style(1); !ok || st.HAlign != HAlignGeneral || st.VAlign != VAlignBottom {
+		t.Fatalf("got default style %+v %t", st, ok)
+	}
+	if _, ok := sheet.Row(0).Style(5); ok {
+		t.Fatal("expected no style for an empty cell")
+	}
+}
@@ -6,41 +6,52 @@ import (
 	"os"
 
 	"github.com/kardianos/xls/ole2"
+	"github.com/kardianos/xls/vba"
 )
 
 // OpenReader opens an XLS file from r with charset.
 // Charset may be "utf-8".
 // If r is a closer, r.Close will be called when WorkBook.Close is called.
 func OpenReader(r io.ReadSeeker, charset string) (*WorkBook, error) {
+	return OpenReaderWithPassword(r, charset, "")
+}
+
+// OpenReaderWithPassword opens an encrypted XLS file from r with charset.
+// An empty password tries the default password Excel uses for write
+// protected files and returns ErrPasswordRequired if that fails.
+func OpenReaderWithPassword(r io.ReadSeeker, charset, password string) (*WorkBook, error) {
 	ole, err := ole2.Open(r, charset)
 	if err != nil {
 		return nil, err
 	}
-	dir, err := ole.ListDir()
+	root, err := ole.Root()
 	if err != nil {
 		return nil, err
 	}
-	var book, root *ole2.File
-	for _, f := range dir {
-		switch f.Name() {
-		case "Workbook":
-			book = f
-		case "Book":
-			book = f
-		case "Root Entry":
-			root = f
-		}
-	}
+	// Only the top level stream is the workbook, embedded objects
+	// may carry their own.
+	book := root.Child("Workbook")
 	if book == nil {
+		book = root.Child("Book")
+	}
+	if book == nil || !book.IsStream() {
 		return nil, fmt.Errorf("No OLE2 Excel Workbook found")
 	}
 	c, isc := r.(io.Closer)
-	of := ole.OpenFile(book, root)
-	wb, err := newWorkBookFromOle2(of)
+	of, err := ole.OpenEntry(book)
 	if err != nil {
-		c.Close()
 		return nil, err
 	}
+	wb, err := newWorkBookFromOle2(of, password)
+	if err != nil {
+		if isc {
+			c.Close()
+		}
+		return nil, err
+	}
+	wb.ole, wb.root = ole, root
+	wb.Properties.read(ole, root)
+	wb.Author = wb.Properties.Author
 	if isc {
 		wb.closer = c
 	}
@@ -49,11 +60,16 @@ func OpenReader(r io.ReadSeeker, charset string) (*WorkBook, error) {
 
 // Open a XLS file from disk with the given charset.
 func Open(name, charset string) (*WorkBook, error) {
+	return OpenWithPassword(name, charset, "")
+}
+
+// OpenWithPassword opens an encrypted XLS file from disk with the given charset.
+func OpenWithPassword(name, charset, password string) (*WorkBook, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return nil, err
 	}
-	wb, err := OpenReader(f, charset)
+	wb, err := OpenReaderWithPassword(f, charset, password)
 	if err != nil {
 		return nil, err
 	}
@@ -68,3 +84,15 @@ func (w *WorkBook) Close() error {
 	}
 	return nil
 }
+
+// VBAProject reads the VBA project of the workbook, nil if it has none.
+func (w *WorkBook) VBAProject() (*vba.Project, error) {
+	if w.root == nil {
+		return nil, nil
+	}
+	storage := w.root.Child("_VBA_PROJECT_CUR")
+	if storage == nil || !storage.IsStorage() {
+		return nil, nil
+	}
+	return vba.Open(w.ole, storage)
+}